@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Before mob deletes or rewrites a wip branch, it keeps its last commit in a local backup ref
+// refs/mob-backup/<timestamp>/<branch>, which 'mob restore' brings back. It is kept outside of refs/mob/, as fetching
+// refs/mob/ prunes every ref the remote does not have.
+const backupRefPrefix = "refs/mob-backup/"
+
+const backupTimestampLayout = "20060102T150405.000Z"
+
+type backup struct {
+	Ref       string
+	Timestamp time.Time
+	Branch    string
+	Commit    string
+	Subject   string
+}
+
+func (b backup) name() string {
+	return strings.TrimPrefix(b.Ref, backupRefPrefix)
+}
+
+// backs up the local and the remote branch. If one of them contains the other, e.g., as someone else pushed
+// commits you haven't pulled yet, only that one is backed up. Otherwise the local branch is the latest backup.
+func backupBranch(configuration Configuration, branch Branch) {
+	local := silentgitignorefailure("rev-parse", "--verify", "refs/heads/"+branch.Name)
+	remote := remoteBranchCommit(branch, configuration)
+	switch {
+	case remote == "" || remote == local || (local != "" && isAncestor(remote, local)):
+		backupCommit(configuration, branch, local)
+	case local == "" || isAncestor(local, remote):
+		backupCommit(configuration, branch, remote)
+	default:
+		backupCommit(configuration, branch, remote)
+		backupCommit(configuration, branch, local)
+	}
+}
+
+func backupCommit(configuration Configuration, branch Branch, commit string) {
+	if commit == "" {
+		debugInfo("nothing to back up for " + branch.Name)
+		return
+	}
+
+	pruneBackups(configuration)
+	timestamp := time.Now().UTC()
+	ref := backupRefPrefix + timestamp.Format(backupTimestampLayout) + "/" + branch.Name
+	for silentgitignorefailure("rev-parse", "--verify", "--quiet", ref) != "" {
+		// two backups of the same branch within a millisecond
+		timestamp = timestamp.Add(time.Millisecond)
+		ref = backupRefPrefix + timestamp.Format(backupTimestampLayout) + "/" + branch.Name
+	}
+	silentgit("update-ref", ref, commit)
+	sayInfo("backed up '" + branch.Name + "' in " + ref)
+}
+
+func listBackups() []backup {
+	output := silentgitignorefailure("for-each-ref", "--sort=-refname", "--format=%(refname)%09%(objectname)%09%(subject)", backupRefPrefix)
+	var backups []backup
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[0], backupRefPrefix)
+		if !strings.Contains(name, "/") {
+			continue
+		}
+		timestamp, err := time.Parse(backupTimestampLayout, name[:strings.Index(name, "/")])
+		if err != nil {
+			debugInfo("skipping backup with unexpected name " + fields[0])
+			continue
+		}
+		backups = append(backups, backup{
+			Ref:       fields[0],
+			Timestamp: timestamp,
+			Branch:    name[strings.Index(name, "/")+1:],
+			Commit:    fields[1],
+			Subject:   fields[2],
+		})
+	}
+	return backups
+}
+
+func pruneBackups(configuration Configuration) {
+	retention, err := parseAge(configuration.BackupRetention)
+	if err != nil {
+		sayWarning("Skipped pruning backups, because MOB_BACKUP_RETENTION is not a valid age (" + configuration.BackupRetention + ")")
+		return
+	}
+	for _, b := range listBackups() {
+		if time.Since(b.Timestamp) > retention {
+			debugInfo("pruning backup " + b.Ref)
+			silentgit("update-ref", "-d", b.Ref)
+		}
+	}
+}
+
+// parseAge understands go durations like '36h' as well as days and weeks like '14d' and '2w'
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(value, suffix) {
+			count, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+			if err != nil || count < 0 {
+				return 0, errors.New("invalid age " + value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, errors.New("invalid age " + value)
+	}
+	return age, nil
+}
+
+func restore(configuration Configuration, parameter []string) {
+	if len(parameter) > 0 && parameter[0] == "--list" {
+		restoreList()
+		return
+	}
+
+	var selected *backup
+	backups := listBackups()
+	if len(parameter) > 0 {
+		selected = findBackup(backups, parameter[0])
+	} else {
+		_, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+		selected = findBackup(backups, currentWipBranch.Name)
+	}
+	if selected == nil {
+		sayError("cannot restore; there is no matching backup")
+		sayFix("To list all backups, use", configuration.mob("restore --list"))
+		return
+	}
+
+	restoreBackup(configuration, *selected)
+}
+
+// name is either a branch, which selects its latest backup, or the name of a backup
+func findBackup(backups []backup, name string) *backup {
+	for _, b := range backups {
+		if b.Branch == name || b.name() == name || b.Ref == name {
+			return &b
+		}
+	}
+	return nil
+}
+
+func restoreList() {
+	backups := listBackups()
+	if len(backups) == 0 {
+		sayInfo("there are no backups")
+		return
+	}
+	sayInfo("backups (newest first):")
+	for _, b := range backups {
+		sayInfoIndented(b.name() + " " + b.Commit[:7] + " " + b.Subject)
+	}
+}
+
+func restoreBackup(configuration Configuration, b backup) {
+	branch := newBranch(b.Branch)
+	localCommit := silentgitignorefailure("rev-parse", "--verify", "refs/heads/"+branch.Name)
+	if localCommit != "" && localCommit != b.Commit && !isAncestor(localCommit, b.Commit) {
+		sayError("cannot restore; the local branch '" + branch.Name + "' contains commits which are not part of the backup " + b.name())
+		sayFix("To keep these commits, rename the local branch first, use", "git branch -m "+branch.Name+" <new-name>")
+		return
+	}
+
+	if gitCurrentBranch().Is(branch.Name) {
+		git("merge", "--ff-only", b.Commit)
+	} else {
+		git("branch", "--force", branch.Name, b.Commit)
+	}
+	gitWithoutEmptyStrings("push", configuration.gitHooksOption(), "--force-with-lease", branch.remoteName(configuration), branch.Name)
+	sayInfo("restored '" + branch.Name + "' and '" + branch.remote(configuration).Name + "' from backup " + b.name())
+	if branch.IsWipBranch(configuration) && !gitCurrentBranch().Is(branch.Name) {
+		sayNext("To continue the session, use", configuration.mob("start"))
+	}
+}
+
+func isAncestor(ancestor string, commit string) bool {
+	_, _, err := runCommand("git", "merge-base", "--is-ancestor", ancestor, commit)
+	return err == nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResetCreatesBackupAndRestoreBringsItBack(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	wipCommit := silentgit("rev-parse", "mob-session")
+
+	reset(configuration)
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+
+	restore(configuration, []string{"mob-session"})
+
+	equals(t, wipCommit, silentgit("rev-parse", "mob-session"))
+	equals(t, wipCommit, silentgit("rev-parse", "origin/mob-session"))
+	assertOutputContains(t, output, "restored 'mob-session' and 'origin/mob-session' from backup")
+}
+
+func TestResetBacksUpRemoteCommitsNotPulledYet(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	remoteWipCommit := silentgit("rev-parse", "mob-session")
+
+	setWorkingDir(tempDir + "/local")
+	reset(configuration)
+
+	equals(t, remoteWipCommit, findBackup(listBackups(), "mob-session").Commit)
+	restore(configuration, []string{"mob-session"})
+	equals(t, remoteWipCommit, silentgit("rev-parse", "origin/mob-session"))
+}
+
+func TestResetBacksUpDivergedLocalAndRemoteCommits(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	remoteWipCommit := silentgit("rev-parse", "mob-session")
+
+	setWorkingDir(tempDir + "/local")
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "local work")
+	localWipCommit := silentgit("rev-parse", "mob-session")
+	reset(configuration)
+
+	backups := listBackups()
+	equals(t, 2, len(backups))
+	equals(t, localWipCommit, backups[0].Commit)
+	equals(t, remoteWipCommit, backups[1].Commit)
+}
+
+func TestBackupsSurviveFetchingTheMobRefs(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	reset(configuration)
+
+	fetchBranches(configuration, newBranch("master"), newBranch("mob-session"))
+
+	equals(t, true, findBackup(listBackups(), "mob-session") != nil)
+}
+
+func TestRestoreWithoutBranchUsesCurrentWipBranch(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	wipCommit := silentgit("rev-parse", "mob-session")
+	reset(configuration)
+
+	restore(configuration, []string{})
+
+	equals(t, wipCommit, silentgit("rev-parse", "mob-session"))
+}
+
+func TestRestoreList(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	reset(configuration)
+
+	restore(configuration, []string{"--list"})
+
+	assertOutputContains(t, output, "/mob-session")
+	assertOutputContains(t, output, configuration.WipCommitMessage)
+}
+
+func TestRestoreRefusesToDropLocalCommits(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	reset(configuration)
+	git("checkout", "-b", "mob-session")
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "other work")
+	git("checkout", "master")
+
+	restore(configuration, []string{"mob-session"})
+
+	assertOutputContains(t, output, "cannot restore; the local branch 'mob-session' contains commits which are not part of the backup")
+}
+
+func TestPruneBackups(t *testing.T) {
+	_, configuration := setup(t)
+	old := backupRefPrefix + time.Now().UTC().Add(-15*24*time.Hour).Format(backupTimestampLayout) + "/mob-session"
+	recent := backupRefPrefix + time.Now().UTC().Add(-13*24*time.Hour).Format(backupTimestampLayout) + "/mob-session"
+	silentgit("update-ref", old, "HEAD")
+	silentgit("update-ref", recent, "HEAD")
+
+	pruneBackups(configuration)
+
+	equals(t, []string{recent}, listMobRefs(backupRefPrefix))
+}
+
+func TestParseAge(t *testing.T) {
+	age, err := parseAge("14d")
+	equals(t, 14*24*time.Hour, age)
+	equals(t, nil, err)
+
+	age, err = parseAge("2w")
+	equals(t, 14*24*time.Hour, age)
+	equals(t, nil, err)
+
+	age, err = parseAge("36h")
+	equals(t, 36*time.Hour, age)
+	equals(t, nil, err)
+
+	_, err = parseAge("soon")
+	equals(t, true, err != nil)
+}
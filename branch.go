@@ -25,7 +25,15 @@ func (branch Branch) Is(branchName string) bool {
 }
 
 func (branch Branch) remote(configuration Configuration) Branch {
-	return newBranch(configuration.RemoteName + "/" + branch.Name)
+	return newBranch(branch.remoteName(configuration) + "/" + branch.Name)
+}
+
+// wip branches live on MOB_WIP_REMOTE_NAME, all other branches on MOB_REMOTE_NAME
+func (branch Branch) remoteName(configuration Configuration) string {
+	if configuration.WipRemoteName != "" && branch.IsWipBranch(configuration) {
+		return configuration.WipRemoteName
+	}
+	return configuration.RemoteName
 }
 
 func (branch Branch) hasRemoteBranch(configuration Configuration) bool {
@@ -44,82 +52,18 @@ func (branch Branch) hasRemoteBranch(configuration Configuration) bool {
 }
 
 func (branch Branch) IsWipBranch(configuration Configuration) bool {
-	if branch.Name == "mob-session" {
+	if configuration.LegacySessionBranch && branch.Name == "mob-session" {
 		return true
 	}
 
-	return strings.Index(branch.Name, configuration.WipBranchPrefix) == 0
-}
-
-func (branch Branch) addWipPrefix(configuration Configuration) Branch {
-	return newBranch(configuration.WipBranchPrefix + branch.Name)
-}
-
-func (branch Branch) addWipQualifier(configuration Configuration) Branch {
-	if configuration.customWipBranchQualifierConfigured() {
-		if branch.Name == configuration.WipBranchPrefix {
-			return newBranch(addSuffix(branch.Name, configuration.WipBranchQualifier))
-		}
-		return newBranch(addSuffix(branch.Name, configuration.wipBranchQualifierSuffix()))
-	}
-	return branch
-}
-
-func addSuffix(branch string, suffix string) string {
-	return branch + suffix
-}
-
-func (branch Branch) removeWipPrefix(configuration Configuration) Branch {
-	return newBranch(removePrefix(branch.Name, configuration.WipBranchPrefix))
-}
-
-func removePrefix(branch string, prefix string) string {
-	if !strings.HasPrefix(branch, prefix) {
-		return branch
-	}
-	return branch[len(prefix):]
-}
-
-func (branch Branch) removeWipQualifier(localBranches []string, configuration Configuration) Branch {
-	for !branch.exists(localBranches) && branch.hasWipBranchQualifierSeparator(configuration) {
-		afterRemoval := branch.removeWipQualifierSuffixOrSeparator(configuration)
-
-		if branch == afterRemoval { // avoids infinite loop
-			break
-		}
-
-		branch = afterRemoval
-	}
-	return branch
-}
-
-func (branch Branch) removeWipQualifierSuffixOrSeparator(configuration Configuration) Branch {
-	if !configuration.customWipBranchQualifierConfigured() { // WipBranchQualifier not configured
-		return branch.removeFromSeparator(configuration.WipBranchQualifierSeparator)
-	} else { // WipBranchQualifier not configured
-		return branch.removeWipQualifierSuffix(configuration)
-	}
-}
-
-func (branch Branch) removeFromSeparator(separator string) Branch {
-	return newBranch(branch.Name[:strings.LastIndex(branch.Name, separator)])
-}
-
-func (branch Branch) removeWipQualifierSuffix(configuration Configuration) Branch {
-	if strings.HasSuffix(branch.Name, configuration.wipBranchQualifierSuffix()) {
-		return newBranch(branch.Name[:strings.LastIndex(branch.Name, configuration.wipBranchQualifierSuffix())])
-	}
-	return branch
+	_, matches := configuration.wipBranchTemplate().match(branch.Name, nil)
+	return matches
 }
 
 func (branch Branch) exists(existingBranches []string) bool {
 	return stringContains(existingBranches, branch.Name)
 }
 
-func (branch Branch) hasWipBranchQualifierSeparator(configuration Configuration) bool { //TODO improve (dont use strings.Contains, add tests)
-	return strings.Contains(branch.Name, configuration.WipBranchQualifierSeparator)
-}
-
 func (branch Branch) hasLocalCommits(configuration Configuration) bool {
 	local := silentgit("for-each-ref", "--format=%(objectname)", "refs/heads/"+branch.Name)
 	remote := silentgit("for-each-ref", "--format=%(objectname)", "refs/remotes/"+branch.remote(configuration).Name)
@@ -142,6 +86,10 @@ func (branch Branch) hasUnpushedCommits(configuration Configuration) bool {
 	return unpushedCommits
 }
 
+func sayUnpushedCommits(branch Branch, configuration Configuration) {
+	sayInfoIndented(silentgit("log", "--pretty=format:%h %s", "refs/remotes/"+branch.remote(configuration).Name+"..refs/heads/"+branch.Name))
+}
+
 func stringContains(list []string, element string) bool {
 	found := false
 	for i := 0; i < len(list); i++ {
@@ -156,16 +104,9 @@ func (branch Branch) isOrphanWipBranch(configuration Configuration) bool {
 	return branch.IsWipBranch(configuration) && !branch.hasRemoteBranch(configuration)
 }
 
-func branch(configuration Configuration) {
-	say(silentgit("branch", "--list", "--remote", newBranch("*").addWipPrefix(configuration).remote(configuration).Name))
-
-	// DEPRECATED
-	say(silentgit("branch", "--list", "--remote", newBranch("mob-session").remote(configuration).Name))
-}
-
 func determineBranches(currentBranch Branch, localBranches []string, configuration Configuration) (baseBranch Branch, wipBranch Branch) {
-	if currentBranch.Is("mob-session") || (currentBranch.Is("master") && !configuration.customWipBranchQualifierConfigured()) {
-		// DEPRECATED
+	if configuration.LegacySessionBranch && (currentBranch.Is("mob-session") || (currentBranch.Is("master") && !configuration.customWipBranchQualifierConfigured() && configuration.WipBranchTemplate == "")) {
+		// DEPRECATED; mob-session always belongs to master, whatever the default branch is, until 'mob migrate'
 		baseBranch = newBranch("master")
 		wipBranch = newBranch("mob-session")
 	} else {
@@ -186,38 +127,49 @@ func getWipBranch(currentBranch Branch, configuration Configuration) Branch {
 		return currentBranch
 	}
 
-	var branch Branch
-	if configuration.customFixedBaseBranchConfigured() && configuration.customWipBranchQualifierConfigured() {
-		branch = newBranch("")
-	} else {
-		branch = currentBranch
-	}
-	return branch.addWipPrefix(configuration).addWipQualifier(configuration)
+	template := configuration.wipBranchTemplate()
+	return newBranch(template.render(currentBranch.Name, configuration.WipBranchQualifier, wipBranchUser(template)))
 }
 
 func getBaseBranch(currentBranch Branch, localBranches []string, configuration Configuration) Branch {
+	if currentBranch.IsWipBranch(configuration) {
+		if meta, recorded := readSessionMeta(currentBranch); recorded && meta.BaseBranch != "" {
+			return newBranch(meta.BaseBranch)
+		}
+	}
 	if configuration.customFixedBaseBranchConfigured() {
 		return newBranch(configuration.FixedBaseBranch)
-	} else if currentBranch.IsWipBranch(configuration) {
-		return currentBranch.removeWipPrefix(configuration).removeWipQualifier(localBranches, configuration)
+	} else if baseBranch, _, ok := parseWipBranch(currentBranch, localBranches, configuration); ok {
+		return baseBranch
 	}
 	return currentBranch
 }
 
+// asks the remote with git ls-remote, so only use it where mob talks to the remote anyway
 func getWipBranchesForBaseBranch(currentBaseBranch Branch, configuration Configuration) []string {
-	remoteBranches := gitRemoteBranches()
-	debugInfo("check on current base branch " + currentBaseBranch.String() + " with remote branches " + strings.Join(remoteBranches, ","))
-
-	remoteBranchWithQualifier := currentBaseBranch.addWipPrefix(configuration).addWipQualifier(configuration).remote(configuration).Name
-	remoteBranchNoQualifier := currentBaseBranch.addWipPrefix(configuration).remote(configuration).Name
-	if currentBaseBranch.Is("master") {
+	patterns := configuration.wipBranchTemplate().globs(currentBaseBranch.Name)
+	if configuration.LegacySessionBranch && currentBaseBranch.Is("master") {
 		// LEGACY
-		remoteBranchNoQualifier = "mob-session"
+		patterns = append(patterns, "mob-session")
 	}
+	return filterWipBranchesForBaseBranch(currentBaseBranch, gitLsRemoteBranches(configuration.wipRemoteName(), patterns...), configuration)
+}
+
+// the wip branches of the base branch as of the last fetch, without asking the remote
+func getFetchedWipBranchesForBaseBranch(currentBaseBranch Branch, configuration Configuration) []string {
+	return filterWipBranchesForBaseBranch(currentBaseBranch, gitRemoteBranches(), configuration)
+}
+
+func filterWipBranchesForBaseBranch(currentBaseBranch Branch, remoteBranches []string, configuration Configuration) []string {
+	debugInfo("check on current base branch " + currentBaseBranch.String() + " with remote branches " + strings.Join(remoteBranches, ","))
 
 	var result []string
 	for _, remoteBranch := range remoteBranches {
-		if strings.Contains(remoteBranch, remoteBranchWithQualifier) || strings.Contains(remoteBranch, remoteBranchNoQualifier) {
+		if !strings.HasPrefix(remoteBranch, configuration.wipRemoteName()+"/") {
+			continue
+		}
+		wipBranch := newBranch(strings.TrimPrefix(remoteBranch, configuration.wipRemoteName()+"/"))
+		if _, ok := sessionQualifier(wipBranch, currentBaseBranch, configuration); ok {
 			result = append(result, remoteBranch)
 		}
 	}
@@ -225,6 +177,51 @@ func getWipBranchesForBaseBranch(currentBaseBranch Branch, configuration Configu
 	return result
 }
 
+// the default branch of the remote, i.e., MOB_DEFAULT_BRANCH or the remote HEAD, falling back to main and master
+func defaultBranch(localBranches []string, configuration Configuration) Branch {
+	if configuration.DefaultBranch != "" {
+		return newBranch(configuration.DefaultBranch)
+	}
+	if remoteHead := gitRemoteHead(configuration.RemoteName); remoteHead != "" {
+		return newBranch(remoteHead)
+	}
+	debugInfo("could not determine the default branch of " + configuration.RemoteName + ", falling back to main or master")
+	if newBranch("main").exists(localBranches) {
+		return newBranch("main")
+	}
+	return newBranch("master")
+}
+
+// the remote HEAD is looked up once per invocation, as parsing wip branches may need it for every branch
+var remoteHeads = map[string]string{}
+var remoteHeadsAsked = map[string]bool{}
+
+// the branch refs/remotes/<remote>/HEAD points to, as of the last clone, 'git remote set-head' or updateRemoteHead
+func gitRemoteHead(remoteName string) string {
+	key := workingDir + ":" + remoteName
+	remoteHead, known := remoteHeads[key]
+	if !known {
+		remoteHead = strings.TrimPrefix(silentgitignorefailure("symbolic-ref", "--quiet", "refs/remotes/"+remoteName+"/HEAD"), "refs/remotes/"+remoteName+"/")
+		remoteHeads[key] = remoteHead
+	}
+	return remoteHead
+}
+
+// asks the remote for its HEAD if it is not known yet. Only commands which fetch do this, so the others
+// never wait for the network.
+func updateRemoteHead(configuration Configuration) {
+	if configuration.DefaultBranch != "" || gitRemoteHead(configuration.RemoteName) != "" {
+		return
+	}
+	key := workingDir + ":" + configuration.RemoteName
+	if remoteHeadsAsked[key] {
+		return
+	}
+	remoteHeadsAsked[key] = true
+	silentgitignorefailure("remote", "set-head", configuration.RemoteName, "--auto")
+	delete(remoteHeads, key)
+}
+
 func hasLocalBranch(localBranch string) bool {
 	localBranches := gitBranches()
 	debugInfo("Local Branches: " + strings.Join(localBranches, "\n"))
@@ -247,6 +244,44 @@ func gitRemoteBranches() []string {
 	return strings.Split(silentgit("branch", "--remotes", "--format=%(refname:short)"), "\n")
 }
 
+// lists the branches on the remote matching the patterns, e.g., mob/main*, without fetching them
+func gitLsRemoteBranches(remoteName string, patterns ...string) []string {
+	if len(patterns) == 0 {
+		return []string{}
+	}
+	args := []string{"ls-remote", "--heads", remoteName}
+	for _, pattern := range patterns {
+		args = append(args, "refs/heads/"+pattern)
+	}
+	var branches []string
+	for _, line := range strings.Split(silentgit(args...), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			branches = append(branches, remoteName+"/"+strings.TrimPrefix(fields[1], "refs/heads/"))
+		}
+	}
+	return branches
+}
+
+// the commits of the given branches on the remote, by remote branch, e.g., origin/main
+func gitLsRemoteCommits(remoteName string, names ...string) map[string]string {
+	commits := map[string]string{}
+	if len(names) == 0 {
+		return commits
+	}
+	args := []string{"ls-remote", "--heads", remoteName}
+	for _, name := range names {
+		args = append(args, "refs/heads/"+name)
+	}
+	for _, line := range strings.Split(silentgit(args...), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			commits[remoteName+"/"+strings.TrimPrefix(fields[1], "refs/heads/")] = fields[0]
+		}
+	}
+	return commits
+}
+
 func gitCurrentBranch() Branch {
 	// upgrade to branch --show-current when git v2.21 is more widely spread
 	return newBranch(silentgit("rev-parse", "--abbrev-ref", "HEAD"))
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 'mob branch' lists the wip branches on the remote with the state of their sessions.
+// With --json, the output is meant for dashboards and contains nothing but the JSON.
+
+type wipBranchInfo struct {
+	Branch               string `json:"branch"`
+	BaseBranch           string `json:"baseBranch"`
+	Qualifier            string `json:"qualifier"`
+	Creator              string `json:"creator,omitempty"`
+	CommitsAhead         int    `json:"commitsAhead"`
+	LastTypist           string `json:"lastTypist"`
+	LastHandover         string `json:"lastHandover"`
+	Orphaned             bool   `json:"orphaned"`
+	Local                bool   `json:"local"`
+	lastHandoverRelative string
+	commitsKnown         bool
+	wipCommit            string
+	baseCommit           string
+}
+
+func branch(configuration Configuration) {
+	localBranches := gitBranches()
+	var patterns []string
+	if configuration.BranchAllBases {
+		patterns = allWipBranchGlobs(configuration)
+	} else {
+		currentBaseBranch, _ := determineBranches(gitCurrentBranch(), localBranches, configuration)
+		patterns = configuration.wipBranchTemplate().globs(currentBaseBranch.Name)
+		if configuration.LegacySessionBranch && currentBaseBranch.Is("master") {
+			// DEPRECATED
+			patterns = append(patterns, "mob-session")
+		}
+	}
+
+	infos := wipBranchInfos(configuration, lsRemoteWipBranches(configuration, patterns), localBranches)
+	if configuration.BranchMine {
+		infos = filterMine(configuration, infos)
+	}
+
+	if configuration.BranchJson {
+		if infos == nil {
+			infos = []wipBranchInfo{}
+		}
+		// without escaping, the creator stays readable, e.g., "alice <alice@example.com>"
+		var output bytes.Buffer
+		encoder := json.NewEncoder(&output)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(infos)
+		say(strings.TrimSuffix(output.String(), "\n"))
+		return
+	}
+	sayWipBranchInfos(configuration, infos)
+}
+
+// the patterns of the wip branches of all base branches
+func allWipBranchGlobs(configuration Configuration) []string {
+	patterns := configuration.wipBranchTemplate().globs("*")
+	if configuration.LegacySessionBranch {
+		// DEPRECATED
+		patterns = append(patterns, "mob-session")
+	}
+	return patterns
+}
+
+func lsRemoteWipBranches(configuration Configuration, patterns []string) []Branch {
+	var wipBranches []Branch
+	for _, remoteBranch := range gitLsRemoteBranches(configuration.wipRemoteName(), patterns...) {
+		wipBranch := newBranch(strings.TrimPrefix(remoteBranch, configuration.wipRemoteName()+"/"))
+		if wipBranch.IsWipBranch(configuration) {
+			wipBranches = append(wipBranches, wipBranch)
+		}
+	}
+	return wipBranches
+}
+
+// The wip branches and their base branches are fetched with explicit refspecs, but only into FETCH_HEAD, as there
+// may be many wip branches. Their commits are taken from 'git ls-remote' instead of the remote-tracking branches.
+func wipBranchInfos(configuration Configuration, wipBranches []Branch, localBranches []string) []wipBranchInfo {
+	if len(wipBranches) == 0 {
+		return []wipBranchInfo{}
+	}
+	fetchMobRefs(configuration, mobRefsPrefix)
+
+	var infos []wipBranchInfo
+	for _, wipBranch := range wipBranches {
+		info := wipBranchInfo{Branch: wipBranch.Name, Local: wipBranch.exists(localBranches)}
+		if meta, recorded := readSessionMeta(wipBranch); recorded {
+			info.BaseBranch, info.Qualifier, info.Creator = meta.BaseBranch, meta.Qualifier, meta.Creator
+		} else if baseBranch, qualifier, ok := parseWipBranch(wipBranch, localBranches, configuration); ok {
+			info.BaseBranch, info.Qualifier = baseBranch.Name, qualifier
+		} else if wipBranch.Is("mob-session") {
+			info.BaseBranch = "master"
+		}
+		infos = append(infos, info)
+	}
+
+	remoteCommits := lsRemoteWipAndBaseBranches(configuration, infos)
+	for i := range infos {
+		info := &infos[i]
+		info.wipCommit = remoteCommits[newBranch(info.Branch).remote(configuration).Name]
+		info.baseCommit = remoteCommits[newBranch(info.BaseBranch).remote(configuration).Name]
+		info.Orphaned = info.baseCommit == ""
+	}
+	fetchWipAndBaseCommits(configuration, infos)
+
+	for i := range infos {
+		info := &infos[i]
+		if !hasCommit(info.wipCommit) {
+			continue
+		}
+		if !info.Orphaned && hasCommit(info.baseCommit) {
+			info.CommitsAhead, _ = strconv.Atoi(silentgitignorefailure("rev-list", "--count", info.baseCommit+".."+info.wipCommit))
+			info.commitsKnown = true
+		}
+		lastCommit := strings.Split(silentgitignorefailure("log", "-1", "--format=%aN%x1f%cI%x1f%cr", info.wipCommit), "\x1f")
+		if len(lastCommit) == 3 {
+			info.LastTypist, info.LastHandover, info.lastHandoverRelative = lastCommit[0], lastCommit[1], lastCommit[2]
+		}
+	}
+	return infos
+}
+
+// Silently, to keep the JSON clean. The empty --refmap keeps git from updating the remote-tracking branches, and
+// branches whose commits are there already are not fetched again.
+func fetchWipAndBaseCommits(configuration Configuration, infos []wipBranchInfo) {
+	refspecsByRemote := map[string][]string{}
+	for _, info := range infos {
+		for _, branchAndCommit := range [][]string{{info.Branch, info.wipCommit}, {info.BaseBranch, info.baseCommit}} {
+			branch, commit := newBranch(branchAndCommit[0]), branchAndCommit[1]
+			remoteName := branch.remoteName(configuration)
+			refspec := "refs/heads/" + branch.Name
+			if commit != "" && !hasCommit(commit) && !stringContains(refspecsByRemote[remoteName], refspec) {
+				refspecsByRemote[remoteName] = append(refspecsByRemote[remoteName], refspec)
+			}
+		}
+	}
+	for remoteName, refspecs := range refspecsByRemote {
+		silentgitignorefailure(append([]string{"fetch", "--no-tags", "--refmap=", remoteName}, refspecs...)...)
+	}
+}
+
+// the commits of the wip branches and their base branches on the remote, by remote branch, e.g., origin/mob/main
+func lsRemoteWipAndBaseBranches(configuration Configuration, infos []wipBranchInfo) map[string]string {
+	namesByRemote := map[string][]string{}
+	for _, info := range infos {
+		for _, branch := range []Branch{newBranch(info.Branch), newBranch(info.BaseBranch)} {
+			remoteName := branch.remoteName(configuration)
+			if branch.Name != "" && !stringContains(namesByRemote[remoteName], branch.Name) {
+				namesByRemote[remoteName] = append(namesByRemote[remoteName], branch.Name)
+			}
+		}
+	}
+	commits := map[string]string{}
+	for remoteName, names := range namesByRemote {
+		for remoteBranch, commit := range gitLsRemoteCommits(remoteName, names...) {
+			commits[remoteBranch] = commit
+		}
+	}
+	return commits
+}
+
+func hasCommit(commit string) bool {
+	if commit == "" {
+		return false
+	}
+	_, _, err := runCommand("git", "cat-file", "-e", commit+"^{commit}")
+	return err == nil
+}
+
+// sessions you started, joined or committed to
+func filterMine(configuration Configuration, infos []wipBranchInfo) []wipBranchInfo {
+	identity := gitUserIdentity()
+	var mine []wipBranchInfo
+	for _, info := range infos {
+		wipBranch := newBranch(info.Branch)
+		commits := info.wipCommit
+		if !info.Orphaned {
+			commits = info.baseCommit + ".." + commits
+		}
+		if info.Creator == identity || authorsContain(readParticipants(wipBranch), identity) ||
+			silentgitignorefailure("log", "-1", "--format=%H", "--author="+gitUserEmail(), commits) != "" {
+			mine = append(mine, info)
+		}
+	}
+	return mine
+}
+
+func authorsContain(authors []Author, author Author) bool {
+	for _, a := range authors {
+		if a == author {
+			return true
+		}
+	}
+	return false
+}
+
+func sayWipBranchInfos(configuration Configuration, infos []wipBranchInfo) {
+	if len(infos) == 0 {
+		sayInfo("there are no wip branches")
+		return
+	}
+	for _, info := range infos {
+		say(newBranch(info.Branch).remote(configuration).Name)
+		details := []string{"base branch '" + info.BaseBranch + "'"}
+		if info.Orphaned {
+			details = append(details, "orphaned, as the base branch is gone")
+		} else if !info.commitsKnown {
+			details = append(details, "commits not fetched")
+		} else if info.CommitsAhead == 1 {
+			details = append(details, "1 commit ahead")
+		} else {
+			details = append(details, fmt.Sprintf("%d commits ahead", info.CommitsAhead))
+		}
+		if info.LastTypist != "" {
+			details = append(details, "last handover by "+info.LastTypist+" "+info.lastHandoverRelative)
+		}
+		if info.Local {
+			details = append(details, "local copy")
+		}
+		sayInfoIndented(strings.Join(details, ", "))
+	}
+}
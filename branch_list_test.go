@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBranchShowsSessionState(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	branch(configuration)
+
+	assertOutputContains(t, output, "\norigin/mob-session\n")
+	assertOutputContains(t, output, "base branch 'master', 1 commit ahead, last handover by local ")
+	assertOutputContains(t, output, "local copy")
+}
+
+func TestBranchFetchesCommitsOfOtherSessions(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	branch(configuration)
+
+	assertOutputContains(t, output, "base branch 'master', 1 commit ahead, last handover by localother ")
+	equals(t, false, newBranch("mob-session").hasRemoteBranch(configuration))
+}
+
+func TestBranchAllBases(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	setWorkingDir(tempDir + "/localother")
+	git("checkout", "-b", "feature1")
+	git("push", "--set-upstream", "origin", "feature1")
+	start(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	branch(configuration)
+	assertOutputNotContains(t, output, "origin/mob/feature1")
+
+	configuration.BranchAllBases = true
+	branch(configuration)
+	assertOutputContains(t, output, "\norigin/mob/feature1\n")
+	assertOutputContains(t, output, "base branch 'feature1', 0 commits ahead")
+}
+
+func TestBranchShowsOrphanedSession(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/localother")
+	git("checkout", "-b", "feature1")
+	git("push", "--set-upstream", "origin", "feature1")
+	start(configuration)
+	git("push", "origin", "--delete", "feature1")
+
+	setWorkingDir(tempDir + "/local")
+	configuration.BranchAllBases = true
+	branch(configuration)
+
+	assertOutputContains(t, output, "base branch 'feature1', orphaned, as the base branch is gone")
+}
+
+func TestBranchMine(t *testing.T) {
+	output, configuration := setup(t)
+	startSessionsGreenAndBlue(t, configuration)
+	setWorkingDir(tempDir + "/local")
+	joinSession(withQualifier(configuration, "green"))
+
+	configuration.BranchMine = true
+	branch(configuration)
+
+	assertOutputContains(t, output, "\norigin/mob/master-green\n")
+	assertOutputNotContains(t, output, "\norigin/mob/master-blue\n")
+}
+
+func TestBranchJson(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	*output = ""
+
+	configuration.BranchJson = true
+	branch(configuration)
+
+	var infos []wipBranchInfo
+	equals(t, nil, json.Unmarshal([]byte(*output), &infos))
+	equals(t, 1, len(infos))
+	equals(t, "mob-session", infos[0].Branch)
+	equals(t, "master", infos[0].BaseBranch)
+	equals(t, 1, infos[0].CommitsAhead)
+	equals(t, "local", infos[0].LastTypist)
+	equals(t, "local <local@example.com>", infos[0].Creator)
+	equals(t, false, infos[0].Orphaned)
+	equals(t, true, infos[0].Local)
+	assertOutputContains(t, output, `"creator": "local <local@example.com>"`)
+}
+
+func TestBranchJsonWithoutWipBranches(t *testing.T) {
+	output, configuration := setup(t)
+	*output = ""
+
+	configuration.BranchJson = true
+	branch(configuration)
+
+	equals(t, "[]\n", *output)
+}
+
+func withQualifier(configuration Configuration, qualifier string) Configuration {
+	configuration.WipBranchQualifier = qualifier
+	return configuration
+}
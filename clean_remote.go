@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 'mob clean --remote --older-than <age>' deletes the wip branches on the remote whose last commit is older than
+// the given age, i.e., the sessions nobody finished, together with their refs below refs/mob/. Each wip branch is
+// backed up first, so 'mob restore' brings it back.
+
+type staleWipBranch struct {
+	wipBranchInfo
+	UnmergedCommits int
+	Authors         []string
+}
+
+func cleanRemote(configuration Configuration) error {
+	if configuration.CleanOlderThan == "" {
+		sayError("cannot clean the remote; the minimum age of the wip branches to delete is missing")
+		sayFix("To delete the wip branches without commits for 30 days, use", configuration.mob("clean --remote --older-than 30d"))
+		return errors.New("cannot clean the remote; --older-than is missing")
+	}
+	maxAge, err := parseAge(configuration.CleanOlderThan)
+	if err != nil {
+		sayError("cannot clean the remote; '" + configuration.CleanOlderThan + "' is not a valid age")
+		sayFix("Use days, weeks or a duration like 36h, e.g.,", configuration.mob("clean --remote --older-than 30d"))
+		return err
+	}
+
+	wipBranches := lsRemoteWipBranches(configuration, allWipBranchGlobs(configuration))
+	infos := wipBranchInfos(configuration, wipBranches, gitBranches())
+	// the remote-tracking branches are the ones which get backed up and compared against
+	fetchWipAndBaseBranches(configuration, infos)
+	stale := staleWipBranches(infos, time.Now().Add(-maxAge), configuration)
+	if len(stale) == 0 {
+		sayInfo("there are no wip branches on " + configuration.wipRemoteName() + " without commits for more than " + configuration.CleanOlderThan)
+		return nil
+	}
+	sayStaleWipBranches(configuration, stale)
+
+	if configuration.DryRun {
+		sayInfo(fmt.Sprintf("dry run: would back up and delete %d wip branch(es) on %s", len(stale), configuration.wipRemoteName()))
+		return nil
+	}
+	answer := strings.ToLower(readUserInput(fmt.Sprintf("delete %d wip branch(es) on %s? [y/N] ", len(stale), configuration.wipRemoteName())))
+	if answer != "y" && answer != "yes" {
+		sayInfo("nothing deleted")
+		return nil
+	}
+
+	return deleteStaleWipBranches(configuration, stale)
+}
+
+func staleWipBranches(infos []wipBranchInfo, threshold time.Time, configuration Configuration) []staleWipBranch {
+	var stale []staleWipBranch
+	for _, info := range infos {
+		lastCommit, err := time.Parse(time.RFC3339, info.LastHandover)
+		if err != nil {
+			debugInfo("skipping " + info.Branch + ", as the date of its last commit is unknown")
+			continue
+		}
+		if !lastCommit.Before(threshold) {
+			continue
+		}
+		unmerged := unmergedCommits(configuration, info)
+		stale = append(stale, staleWipBranch{
+			wipBranchInfo:   info,
+			UnmergedCommits: len(unmerged),
+			Authors:         distinctAuthors(unmerged),
+		})
+	}
+	return stale
+}
+
+// the author of each commit which is on the wip branch only; if the base branch is gone,
+// these are the commits which are on no other branch of the remote
+func unmergedCommits(configuration Configuration, info wipBranchInfo) []string {
+	remoteWipBranch := newBranch(info.Branch).remote(configuration).Name
+	args := []string{"log", "--format=%aN", remoteWipBranch}
+	if info.Orphaned {
+		args = append(args, "--not", "--exclude="+remoteWipBranch, "--remotes="+configuration.wipRemoteName())
+	} else {
+		args = append(args, "^"+newBranch(info.BaseBranch).remote(configuration).Name)
+	}
+	output := silentgitignorefailure(args...)
+	if output == "" {
+		return []string{}
+	}
+	return strings.Split(output, "\n")
+}
+
+func fetchWipAndBaseBranches(configuration Configuration, infos []wipBranchInfo) {
+	var branches []Branch
+	for _, info := range infos {
+		branches = append(branches, newBranch(info.Branch), newBranch(info.BaseBranch))
+	}
+	commands, missingRemoteBranches := fetchBranchesCommands(configuration, branches...)
+	for _, remoteBranch := range missingRemoteBranches {
+		silentgitignorefailure("update-ref", "-d", "refs/remotes/"+remoteBranch)
+	}
+	for _, args := range commands {
+		silentgit(args...)
+	}
+}
+
+func distinctAuthors(authors []string) []string {
+	var distinct []string
+	seen := map[string]bool{}
+	for _, author := range authors {
+		if !seen[author] {
+			seen[author] = true
+			distinct = append(distinct, author)
+		}
+	}
+	return distinct
+}
+
+func sayStaleWipBranches(configuration Configuration, stale []staleWipBranch) {
+	sayInfo("wip branches on " + configuration.wipRemoteName() + " without commits for more than " + configuration.CleanOlderThan + ":")
+	for _, b := range stale {
+		say(newBranch(b.Branch).remote(configuration).Name)
+		details := []string{"last commit " + b.lastHandoverRelative}
+		if b.Orphaned {
+			details = append(details, "orphaned, as the base branch '"+b.BaseBranch+"' is gone")
+		}
+		if b.UnmergedCommits == 0 {
+			details = append(details, "no unmerged commits")
+		} else {
+			details = append(details, fmt.Sprintf("%d unmerged commit(s) by %s", b.UnmergedCommits, strings.Join(b.Authors, ", ")))
+		}
+		sayInfoIndented(strings.Join(details, ", "))
+	}
+}
+
+func deleteStaleWipBranches(configuration Configuration, stale []staleWipBranch) error {
+	var refs []string
+	var mobRefs []string
+	for _, b := range stale {
+		wipBranch := newBranch(b.Branch)
+		// the remote wip branch is deleted, not the local one
+		backupCommit(configuration, wipBranch, remoteBranchCommit(wipBranch, configuration))
+		refs = append(refs, "refs/heads/"+wipBranch.Name)
+		if _, recorded := readSessionMeta(wipBranch); recorded {
+			mobRefs = append(mobRefs, metaRef(wipBranch))
+		}
+		mobRefs = append(mobRefs, listMobRefs(lockRef(wipBranch))...)
+		mobRefs = append(mobRefs, listMobRefs(participantsRefs(wipBranch))...)
+	}
+	args := append([]string{"push", configuration.gitHooksOption(), configuration.wipRemoteName(), "--delete"}, append(refs, mobRefs...)...)
+	commandString, output, err := runCommand("git", deleteEmptyStrings(args)...)
+	if err != nil {
+		sayGitError(commandString, output, err)
+		sayDeletionSummary(configuration, stale)
+		sayFix("To try again, use", configuration.mob("clean --remote --older-than "+configuration.CleanOlderThan))
+		return err
+	}
+	sayIndented(commandString)
+	for _, ref := range mobRefs {
+		silentgit("update-ref", "-d", ref)
+	}
+
+	sayInfo(fmt.Sprintf("deleted %d wip branch(es) on %s", len(stale), configuration.wipRemoteName()))
+	for _, b := range stale {
+		if hasLocalBranch(b.Branch) {
+			sayNext("To remove your local copies of the deleted wip branches as well, use", configuration.mob("clean"))
+			break
+		}
+	}
+	sayNext("To bring back a deleted wip branch, use", configuration.mob("restore <branch>"))
+	return nil
+}
+
+// the push may have deleted some of the wip branches before it failed, so ask the remote which ones are left
+func sayDeletionSummary(configuration Configuration, stale []staleWipBranch) {
+	remaining := map[string]bool{}
+	for _, wipBranch := range lsRemoteWipBranches(configuration, allWipBranchGlobs(configuration)) {
+		remaining[wipBranch.Name] = true
+	}
+	var deleted, notDeleted []string
+	for _, b := range stale {
+		if remaining[b.Branch] {
+			notDeleted = append(notDeleted, b.Branch)
+		} else {
+			deleted = append(deleted, b.Branch)
+		}
+	}
+	sayError(fmt.Sprintf("deleted %d of %d wip branch(es) on %s; all of them are backed up", len(deleted), len(stale), configuration.wipRemoteName()))
+	if len(notDeleted) > 0 {
+		sayInfo("not deleted:")
+		sayInfoIndented(strings.Join(notDeleted, "\n"))
+	}
+	if len(deleted) > 0 {
+		sayNext("To bring back a deleted wip branch, use", configuration.mob("restore <branch>"))
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCleanRemoteDeletesStaleWipBranches(t *testing.T) {
+	output, configuration := setup(t)
+	startStaleSession(t, configuration)
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	setUserInput(t, "y\n")
+
+	configuration.CleanOlderThan = "30d"
+	err := cleanRemote(configuration)
+
+	equals(t, nil, err)
+	assertOutputContains(t, output, "\norigin/mob/master-old\n")
+	assertOutputContains(t, output, "1 unmerged commit(s) by localother")
+	assertOutputNotContains(t, output, "\norigin/mob-session\n")
+	equals(t, false, newBranch("mob/master-old").hasRemoteBranch(configuration))
+	equals(t, true, newBranch("mob-session").hasRemoteBranch(configuration))
+	equals(t, true, findBackup(listBackups(), "mob/master-old") != nil)
+	fetchMobRefs(configuration, metaRefPrefix)
+	fetchMobRefs(configuration, participantsRefPrefix)
+	_, recorded := readSessionMeta(newBranch("mob/master-old"))
+	equals(t, false, recorded)
+	equals(t, []string{}, listMobRefs(participantsRefs(newBranch("mob/master-old"))))
+}
+
+func TestCleanRemoteDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	startStaleSession(t, configuration)
+	setWorkingDir(tempDir + "/local")
+
+	configuration.CleanOlderThan = "30d"
+	configuration.DryRun = true
+	err := cleanRemote(configuration)
+
+	equals(t, nil, err)
+	assertOutputContains(t, output, "dry run: would back up and delete 1 wip branch(es) on origin")
+	equals(t, true, newBranch("mob/master-old").hasRemoteBranch(configuration))
+}
+
+func TestCleanRemoteWithoutConfirmation(t *testing.T) {
+	output, configuration := setup(t)
+	startStaleSession(t, configuration)
+	setWorkingDir(tempDir + "/local")
+	setUserInput(t, "n\n")
+
+	configuration.CleanOlderThan = "30d"
+	err := cleanRemote(configuration)
+
+	equals(t, nil, err)
+	assertOutputContains(t, output, "nothing deleted")
+	equals(t, true, newBranch("mob/master-old").hasRemoteBranch(configuration))
+}
+
+func TestCleanRemoteWithoutStaleWipBranches(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+
+	configuration.CleanOlderThan = "30d"
+	err := cleanRemote(configuration)
+
+	equals(t, nil, err)
+	assertOutputContains(t, output, "there are no wip branches on origin without commits for more than 30d")
+	equals(t, true, newBranch("mob-session").hasRemoteBranch(configuration))
+}
+
+func TestCleanRemoteRequiresOlderThan(t *testing.T) {
+	output, configuration := setup(t)
+
+	err := cleanRemote(configuration)
+
+	equals(t, true, err != nil)
+	assertOutputContains(t, output, "clean --remote --older-than 30d")
+}
+
+func TestParseArgsCleanRemote(t *testing.T) {
+	configuration := getDefaultConfiguration()
+
+	command, parameters, configuration := parseArgs([]string{"mob", "clean", "--remote", "--older-than", "2w", "--dry-run"}, configuration)
+
+	equals(t, "clean", command)
+	equals(t, "", strings.Join(parameters, ""))
+	equals(t, true, configuration.CleanRemote)
+	equals(t, "2w", configuration.CleanOlderThan)
+	equals(t, true, configuration.DryRun)
+}
+
+func TestCleanRemoteFailingPushSaysWhatIsLeft(t *testing.T) {
+	output, configuration := setup(t)
+	startStaleSession(t, configuration)
+	setWorkingDir(tempDir + "/local")
+	rejectPushes(t)
+	setUserInput(t, "y\n")
+
+	configuration.CleanOlderThan = "30d"
+	err := cleanRemote(configuration)
+
+	equals(t, true, err != nil)
+	assertOutputContains(t, output, "deleted 0 of 1 wip branch(es) on origin; all of them are backed up")
+	assertOutputContains(t, output, "not deleted:\n")
+	assertOutputContains(t, output, "clean --remote --older-than 30d")
+	equals(t, true, newBranch("mob/master-old").hasRemoteBranch(configuration))
+	equals(t, true, findBackup(listBackups(), "mob/master-old") != nil)
+}
+
+func TestCleanRemoteFailingPushExitsWithError(t *testing.T) {
+	_, configuration := setup(t)
+	startStaleSession(t, configuration)
+	setWorkingDir(tempDir + "/local")
+	rejectPushes(t)
+	setUserInput(t, "y\n")
+	configuration.CleanRemote = true
+	configuration.CleanOlderThan = "30d"
+
+	assertExitCode(t, 1, func() { execute("clean", []string{}, configuration) })
+}
+
+// localother starts the session mob/master-old and hands over on 2020-01-01
+func startStaleSession(t *testing.T, configuration Configuration) {
+	setWorkingDir(tempDir + "/localother")
+	configuration.WipBranchQualifier = "old"
+	start(configuration)
+	createFile(t, "old.txt", "contentIrrelevant")
+	os.Setenv("GIT_AUTHOR_DATE", "2020-01-01T12:00:00Z")
+	os.Setenv("GIT_COMMITTER_DATE", "2020-01-01T12:00:00Z")
+	defer os.Unsetenv("GIT_AUTHOR_DATE")
+	defer os.Unsetenv("GIT_COMMITTER_DATE")
+	next(configuration)
+}
+
+// installs a hook in the remote which rejects every push
+func rejectPushes(t *testing.T) {
+	hook := tempDir + "/remote/hooks/pre-receive"
+	if err := ioutil.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
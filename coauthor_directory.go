@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// coauthorDirectory normalises the identities found in the git history.
+// It is read from '.mob-coauthors' in the user home and in the git project root directory:
+//
+//	# the canonical identity, followed by the identities it is also known as
+//	alias Alice <alice@example.com> = alice <alice@personal.example>, <alice@work.example>
+//	# identities which are never added as co-author
+//	exclude dependabot[bot]
+//	# the address used in Co-authored-by trailers, e.g., the GitHub noreply address
+//	noreply Alice <alice@example.com> = 12345+alice@users.noreply.github.com
+//
+// An identity is either 'Name <email>', '<email>' or 'Name'. Emails take precedence over names when matching.
+type coauthorDirectory struct {
+	aliases  []coauthorAlias
+	excluded []coauthorIdentity
+	noreply  []coauthorNoreply
+}
+
+type coauthorIdentity struct {
+	Name  string
+	Email string
+}
+
+type coauthorAlias struct {
+	Canonical coauthorIdentity
+	Aliases   []coauthorIdentity
+}
+
+type coauthorNoreply struct {
+	Identity coauthorIdentity
+	Email    string
+}
+
+const coauthorDirectoryFileName = ".mob-coauthors"
+
+func loadCoauthorDirectory() coauthorDirectory {
+	var paths []string
+	if rootDir := gitRootDir(); rootDir != "" {
+		paths = append(paths, rootDir+"/"+coauthorDirectoryFileName)
+	}
+	if currentUser, err := user.Current(); err == nil {
+		paths = append(paths, currentUser.HomeDir+"/"+coauthorDirectoryFileName)
+	}
+	return readCoauthorDirectory(paths...)
+}
+
+// earlier paths take precedence over later ones
+func readCoauthorDirectory(paths ...string) coauthorDirectory {
+	var directory coauthorDirectory
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			debugInfo("No coauthor directory found. (" + path + ") Error: " + err.Error())
+			continue
+		}
+		debugInfo("Found coauthor directory at " + path)
+		directory = parseCoauthorDirectory(directory, file)
+		file.Close()
+	}
+	return directory
+}
+
+func parseCoauthorDirectory(directory coauthorDirectory, reader io.Reader) coauthorDirectory {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyword := strings.Fields(line)[0]
+		entry := strings.TrimSpace(strings.TrimPrefix(line, keyword))
+		left, right := entry, ""
+		if strings.Contains(entry, "=") {
+			left = strings.TrimSpace(entry[:strings.Index(entry, "=")])
+			right = strings.TrimSpace(entry[strings.Index(entry, "=")+1:])
+		}
+
+		switch keyword {
+		case "alias":
+			alias := coauthorAlias{Canonical: parseCoauthorIdentity(left)}
+			for _, aliasIdentity := range strings.Split(right, ",") {
+				if strings.TrimSpace(aliasIdentity) != "" {
+					alias.Aliases = append(alias.Aliases, parseCoauthorIdentity(aliasIdentity))
+				}
+			}
+			directory.aliases = append(directory.aliases, alias)
+		case "exclude":
+			directory.excluded = append(directory.excluded, parseCoauthorIdentity(left))
+		case "noreply":
+			if right == "" {
+				sayWarning("Skipped coauthor directory line without noreply address (" + line + ")")
+				continue
+			}
+			directory.noreply = append(directory.noreply, coauthorNoreply{Identity: parseCoauthorIdentity(left), Email: right})
+		default:
+			sayWarning("Skipped unknown coauthor directory line (" + line + ")")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		sayWarning("Coauthor directory exists, but could not be read completely.")
+	}
+	return directory
+}
+
+func parseCoauthorIdentity(text string) coauthorIdentity {
+	text = strings.TrimSpace(text)
+	start := strings.Index(text, "<")
+	end := strings.LastIndex(text, ">")
+	if start < 0 || end < start {
+		return coauthorIdentity{Name: text}
+	}
+	return coauthorIdentity{
+		Name:  strings.TrimSpace(text[:start]),
+		Email: strings.TrimSpace(text[start+1 : end]),
+	}
+}
+
+func (identity coauthorIdentity) String() string {
+	if identity.Email == "" {
+		return identity.Name
+	}
+	if identity.Name == "" {
+		return "<" + identity.Email + ">"
+	}
+	return identity.Name + " <" + identity.Email + ">"
+}
+
+// pattern is an identity from the coauthor directory, where name or email may be missing
+func (identity coauthorIdentity) matches(pattern coauthorIdentity) bool {
+	if pattern.Email != "" {
+		return strings.EqualFold(identity.Email, pattern.Email)
+	}
+	return pattern.Name != "" && identity.Name == pattern.Name
+}
+
+func (directory coauthorDirectory) resolve(author Author) Author {
+	identity := parseCoauthorIdentity(author)
+	for _, alias := range directory.aliases {
+		if identity.matches(alias.Canonical) {
+			return alias.Canonical.String()
+		}
+		for _, aliasIdentity := range alias.Aliases {
+			if identity.matches(aliasIdentity) {
+				return alias.Canonical.String()
+			}
+		}
+	}
+	return identity.String()
+}
+
+func (directory coauthorDirectory) isExcluded(author Author) bool {
+	for _, candidate := range []Author{author, directory.resolve(author)} {
+		identity := parseCoauthorIdentity(candidate)
+		for _, excluded := range directory.excluded {
+			if identity.matches(excluded) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// the identity to use in Co-authored-by trailers
+func (directory coauthorDirectory) trailerIdentity(author Author) Author {
+	resolved := parseCoauthorIdentity(directory.resolve(author))
+	for _, noreply := range directory.noreply {
+		if resolved.matches(noreply.Identity) {
+			return coauthorIdentity{Name: resolved.Name, Email: noreply.Email}.String()
+		}
+	}
+	return resolved.String()
+}
+
+func (directory coauthorDirectory) name(author Author) string {
+	return parseCoauthorIdentity(directory.resolve(author)).Name
+}
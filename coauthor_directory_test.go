@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testCoauthorDirectory = `
+# comment
+alias Alice <alice@example.com> = alice <alice@personal.example>, <ALICE@work.example>
+alias Bob <bob@example.com> = bobby
+exclude dependabot[bot]
+exclude <ci@example.com>
+noreply Bob <bob@example.com> = 42+bob@users.noreply.github.com
+`
+
+func TestCoauthorDirectoryResolvesAliases(t *testing.T) {
+	directory := parseCoauthorDirectory(coauthorDirectory{}, strings.NewReader(testCoauthorDirectory))
+
+	equals(t, "Alice <alice@example.com>", directory.resolve("alice <alice@personal.example>"))
+	equals(t, "Alice <alice@example.com>", directory.resolve("Alice A. <alice@work.example>"))
+	equals(t, "Bob <bob@example.com>", directory.resolve("bobby <bobby@example.com>"))
+	equals(t, "Carol <carol@example.com>", directory.resolve("Carol <carol@example.com>"))
+}
+
+func TestCoauthorDirectoryExcludes(t *testing.T) {
+	directory := parseCoauthorDirectory(coauthorDirectory{}, strings.NewReader(testCoauthorDirectory))
+
+	equals(t, true, directory.isExcluded("dependabot[bot] <49699333+dependabot[bot]@users.noreply.github.com>"))
+	equals(t, true, directory.isExcluded("CI <ci@example.com>"))
+	equals(t, false, directory.isExcluded("Alice <alice@example.com>"))
+}
+
+func TestCoauthorDirectoryTrailerIdentity(t *testing.T) {
+	directory := parseCoauthorDirectory(coauthorDirectory{}, strings.NewReader(testCoauthorDirectory))
+
+	equals(t, "Bob <42+bob@users.noreply.github.com>", directory.trailerIdentity("bobby <bobby@example.com>"))
+	equals(t, "Alice <alice@example.com>", directory.trailerIdentity("alice <alice@personal.example>"))
+	equals(t, "Bob", directory.name("bobby <bobby@example.com>"))
+}
+
+func TestCoauthorDirectoryEarlierEntriesTakePrecedence(t *testing.T) {
+	directory := parseCoauthorDirectory(coauthorDirectory{}, strings.NewReader("alias Alice Project <alice@example.com> = <alice@personal.example>"))
+	directory = parseCoauthorDirectory(directory, strings.NewReader("alias Alice User <alice@example.com> = <alice@personal.example>"))
+
+	equals(t, "Alice Project <alice@example.com>", directory.resolve("alice <alice@personal.example>"))
+}
+
+func TestNormalizeCoauthors(t *testing.T) {
+	directory := parseCoauthorDirectory(coauthorDirectory{}, strings.NewReader(testCoauthorDirectory))
+
+	actual := removeDuplicateValues(normalizeCoauthors([]Author{
+		"alice <alice@personal.example>",
+		"dependabot[bot] <support@github.com>",
+		"Alice <alice@example.com>",
+		"bobby <bobby@example.com>",
+	}, directory))
+
+	equals(t, []Author{"Alice <alice@example.com>", "Bob <42+bob@users.noreply.github.com>"}, actual)
+}
+
+func TestReadCoauthorDirectoryFromFile(t *testing.T) {
+	tempDir = t.TempDir()
+	setWorkingDir(tempDir)
+	createFile(t, coauthorDirectoryFileName, "exclude <ci@example.com>")
+
+	directory := readCoauthorDirectory(tempDir+"/"+coauthorDirectoryFileName, tempDir+"/does-not-exist")
+
+	equals(t, true, directory.isExcluded("CI <ci@example.com>"))
+}
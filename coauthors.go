@@ -3,9 +3,9 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
-	"regexp"
 	"sort"
 	"strings"
 )
@@ -13,21 +13,33 @@ import (
 // Author is a coauthor "Full Name <email>"
 type Author = string
 
-func collectCoauthorsFromWipCommits(file *os.File) []Author {
-	// Here we parse the SQUASH_MSG file for the list of authors on
-	// the WIP branch.  If this technique later turns out to be
-	// problematic, an alternative would be to instead fetch the
-	// authors' list from the git log, using e.g.:
-	//
-	// silentgit("log", fmt.Sprintf("%s..", currentBaseBranch), "--reverse", "--pretty=format:%an <%ae>")
-	//
+// the commit authors plus the participants who registered on the session without typing
+func collectCoauthorsOfSession(configuration Configuration, baseBranch Branch, wipBranch Branch) []Author {
+	authors := wipCommitAuthors(baseBranch.remote(configuration).Name, wipBranch.Name)
+	participants := collectParticipants(configuration, wipBranch)
+	debugInfo("Registered participants")
+	debugInfo(strings.Join(participants, ","))
+	return prepareCoauthors(append(authors, participants...))
+}
+
+func wipCommitAuthors(baseBranch string, wipBranch string) []Author {
+	// The authors are taken from the git log of the wip branch, which works for every done mode.
 	// For details and background, see https://github.com/remotemobprogramming/mob/issues/81
+	log := silentgitignorefailure("log", baseBranch+".."+wipBranch, "--pretty=format:%aN <%aE>")
+	return removeEmptyValues(strings.Split(strings.TrimSpace(log), "\n"))
+}
 
-	coauthors := parseCoauthors(file)
+func prepareCoauthors(coauthors []Author) []Author {
 	debugInfo("Parsed coauthors")
 	debugInfo(strings.Join(coauthors, ","))
 
+	coauthorDirectory := loadCoauthorDirectory()
+	coauthors = normalizeCoauthors(coauthors, coauthorDirectory)
+	debugInfo("Normalized coauthors")
+	debugInfo(strings.Join(coauthors, ","))
+
 	coauthors = removeElementsContaining(coauthors, gitUserEmail())
+	coauthors = removeElementsContaining(coauthors, coauthorDirectory.trailerIdentity(gitUserIdentity()))
 	debugInfo("Parsed coauthors without committer")
 	debugInfo(strings.Join(coauthors, ","))
 
@@ -42,24 +54,16 @@ func collectCoauthorsFromWipCommits(file *os.File) []Author {
 	return coauthors
 }
 
-func parseCoauthors(file *os.File) []Author {
-	var coauthors []Author
-
-	authorOrCoauthorMatcher := regexp.MustCompile("(?i).*(author)+.+<+.*>+")
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if authorOrCoauthorMatcher.MatchString(line) {
-			author := stripToAuthor(line)
-			coauthors = append(coauthors, author)
+func normalizeCoauthors(coauthors []Author, coauthorDirectory coauthorDirectory) []Author {
+	var result []Author
+	for _, coauthor := range coauthors {
+		if coauthorDirectory.isExcluded(coauthor) {
+			debugInfo("excluding coauthor " + coauthor)
+			continue
 		}
+		result = append(result, coauthorDirectory.trailerIdentity(coauthor))
 	}
-	return coauthors
-}
-
-func stripToAuthor(line string) Author {
-	return strings.TrimSpace(strings.Join(strings.Split(line, ":")[1:], ""))
+	return result
 }
 
 func sortByLength(slice []string) {
@@ -92,42 +96,128 @@ func removeDuplicateValues(slice []string) []string {
 	return result
 }
 
-func appendCoauthorsToSquashMsg(gitDir string) error {
-	squashMsgPath := path.Join(gitDir, "SQUASH_MSG")
-	debugInfo("opening " + squashMsgPath)
-	file, err := os.OpenFile(squashMsgPath, os.O_APPEND|os.O_RDWR, 0644)
-	if err != nil {
-		if os.IsNotExist(err) {
-			debugInfo(squashMsgPath + " does not exist")
-			// No wip commits, nothing to squash, this isn't really an error
-			return nil
+func removeEmptyValues(slice []string) []string {
+	var result []string
+	for _, entry := range slice {
+		if strings.TrimSpace(entry) != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// adds the coauthors to whatever 'mob done' left behind: a pending merge commit, staged changes, or the last commit
+// if 'mob done' created it. Commits which existed before, e.g., merged from the wip branch, are never rewritten.
+func appendCoauthorsToDoneResult(configuration Configuration, gitDir string, coauthors []Author, state doneState) error {
+	if len(coauthors) == 0 {
+		return nil
+	}
+
+	if isMergeInProgress() {
+		return appendCoauthorsToFile(path.Join(gitDir, "MERGE_MSG"), coauthors)
+	}
+
+	if len(getCachedChanges()) > 0 {
+		return appendCoauthorsToFile(path.Join(gitDir, "SQUASH_MSG"), coauthors)
+	}
+
+	lastCommit := gitCommitHash()
+	if state.RemoteBaseCommit == "" || lastCommit == state.RemoteBaseCommit {
+		debugInfo("nothing to add co-authors to")
+		return nil
+	}
+	if isCreatedByDone(lastCommit, state) {
+		return appendCoauthorsToLastCommit(coauthors, configuration)
+	}
+
+	missingCoauthors := removeCoauthorsContainedIn(coauthors, lastCommitMessage())
+	if len(missingCoauthors) > 0 {
+		sayInfo("the merged commits existed before 'mob done', so they are not rewritten; to credit the co-authors, add these trailers yourself:")
+		sayInfoIndented(strings.Join(createCoauthorTrailers(missingCoauthors), "\n"))
+	}
+	return nil
+}
+
+// a commit which no branch contained before 'mob done', e.g., one created by squashing wip commits
+func isCreatedByDone(commit string, state doneState) bool {
+	for _, before := range []string{state.BaseCommit, state.WipCommit, state.RemoteWipCommit, state.RemoteBaseCommit} {
+		if before != "" && isAncestor(commit, before) {
+			return false
 		}
+	}
+	return true
+}
+
+func appendCoauthorsToFile(filePath string, coauthors []Author) error {
+	debugInfo("opening " + filePath)
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
 
+	missingCoauthors := removeCoauthorsContainedIn(coauthors, string(content))
+	if len(missingCoauthors) == 0 {
+		return nil
+	}
+
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
 	defer file.Close()
 
-	// read from repo/.git/SQUASH_MSG
-	coauthors := collectCoauthorsFromWipCommits(file)
+	writer := bufio.NewWriter(file)
+	writer.WriteString(createCommitMessage(missingCoauthors))
+	return writer.Flush()
+}
+
+func appendCoauthorsToLastCommit(coauthors []Author, configuration Configuration) error {
+	commitMessage := lastCommitMessage()
+	missingCoauthors := removeCoauthorsContainedIn(coauthors, commitMessage)
+	if len(missingCoauthors) == 0 {
+		return nil
+	}
 
-	if len(coauthors) > 0 {
-		coauthorSuffix := createCommitMessage(coauthors)
+	sayInfo("adding co-authors to the last commit")
+	commitMessage = strings.TrimSpace(commitMessage) + "\n\n" + strings.Join(createCoauthorTrailers(missingCoauthors), "\n")
+	gitWithoutEmptyStrings("commit", "--amend", "--allow-empty", "--cleanup=verbatim", "--message", commitMessage, configuration.gitHooksOption())
+	return nil
+}
 
-		// append to repo/.git/SQUASH_MSG
-		writer := bufio.NewWriter(file)
-		writer.WriteString(coauthorSuffix)
-		err = writer.Flush()
+func removeCoauthorsContainedIn(coauthors []Author, commitMessage string) []Author {
+	var result []Author
+	for _, coauthor := range coauthors {
+		if !strings.Contains(commitMessage, "Co-authored-by: "+coauthor) {
+			result = append(result, coauthor)
+		}
 	}
+	return result
+}
 
-	return err
+func filterCoauthorTrailers(commitMessage string) []string {
+	var trailers []string
+	for _, line := range strings.Split(commitMessage, "\n") {
+		if strings.HasPrefix(line, "Co-authored-by: ") {
+			trailers = append(trailers, strings.TrimSpace(line))
+		}
+	}
+	return trailers
 }
 
 func createCommitMessage(coauthors []Author) string {
 	commitMessage := "\n\n"
 	commitMessage += "# automatically added all co-authors from WIP commits\n"
 	commitMessage += "# add missing co-authors manually\n"
-	for _, coauthor := range coauthors {
-		commitMessage += fmt.Sprintf("Co-authored-by: %s\n", coauthor)
+	for _, trailer := range createCoauthorTrailers(coauthors) {
+		commitMessage += trailer + "\n"
 	}
 	return commitMessage
 }
+
+func createCoauthorTrailers(coauthors []Author) []string {
+	var trailers []string
+	for _, coauthor := range coauthors {
+		trailers = append(trailers, fmt.Sprintf("Co-authored-by: %s", coauthor))
+	}
+	return trailers
+}
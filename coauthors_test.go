@@ -44,6 +44,95 @@ func TestStartDoneCoAuthors(t *testing.T) {
 	assertOutputContains(t, output, "\nCo-authored-by: bob <bob@example.com>\nCo-authored-by: alice <alice@example.com>\nCo-authored-by: localother <localother@example.com>\n")
 }
 
+func TestStartDoneNoSquashDoesNotRewriteMergedCommits(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.DoneSquash = NoSquash
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	start(configuration)
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "manual commit")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	manualCommit := silentgit("rev-parse", "origin/mob-session")
+	done(configuration)
+
+	equals(t, manualCommit, silentgit("rev-parse", "HEAD"))
+	equals(t, "manual commit", silentgit("log", "-1", "--pretty=format:%B"))
+	assertOutputContains(t, output, "so they are not rewritten; to credit the co-authors, add these trailers yourself:")
+	assertOutputContains(t, output, "Co-authored-by: bob <bob@example.com>\n")
+}
+
+func TestStartDoneSquashWipCoAuthorsInCreatedLastCommit(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = SquashWip
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	start(configuration)
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "manual commit")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	manualCommit := silentgit("rev-parse", "origin/mob-session")
+	done(configuration)
+
+	equals(t, false, manualCommit == silentgit("rev-parse", "HEAD"))
+	output := run(t, "git", "log", "-1", "--pretty=format:%B")
+	assertOutputContains(t, output, "manual commit\n\nCo-authored-by: bob <bob@example.com>\nCo-authored-by: alice <alice@example.com>")
+}
+
+func TestStartDoneNoSquashCoAuthorsInSquashMsg(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = NoSquash
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	done(configuration)
+
+	output := run(t, "cat", tempDir+"/local/.git/SQUASH_MSG")
+	assertOutputContains(t, output, "\nCo-authored-by: alice <alice@example.com>\n")
+}
+
+func TestStartDoneSquashWipCoAuthors(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = SquashWip
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFileAndCommitIt(t, "file1.txt", "contentIrrelevant", "manual commit")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	done(configuration)
+
+	output := run(t, "cat", tempDir+"/local/.git/SQUASH_MSG")
+	assertOutputContains(t, output, "\nCo-authored-by: bob <bob@example.com>\nCo-authored-by: alice <alice@example.com>\n")
+}
+
 func TestCreateCommitMessage(t *testing.T) {
 	equals(t, `
 
@@ -62,6 +151,14 @@ func TestSortByLength(t *testing.T) {
 	equals(t, []string{"b", "aa"}, slice)
 }
 
+func TestRemoveCoauthorsContainedIn(t *testing.T) {
+	coauthors := []Author{"Alice <alice@example.com>", "Bob <bob@example.com>"}
+
+	actual := removeCoauthorsContainedIn(coauthors, "message\n\nCo-authored-by: Alice <alice@example.com>")
+
+	equals(t, []Author{"Bob <bob@example.com>"}, actual)
+}
+
 func TestRemoveDuplicateValues(t *testing.T) {
 	slice := []string{"aa", "b", "c", "b"}
 
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path"
 	"runtime"
 	"strconv"
 	"strings"
@@ -192,3 +195,68 @@ func sayLastCommitsList(currentBaseBranch string, currentWipBranch string) {
 func hasUncommittedChanges() bool {
 	return !isNothingToCommit()
 }
+
+func commitDone(configuration Configuration, baseBranch Branch, remoteBaseCommit string) {
+	git("fetch", configuration.RemoteName, baseBranch.Name)
+	if remoteBranchCommit(baseBranch, configuration) != remoteBaseCommit {
+		sayError("cannot commit; " + baseBranch.remote(configuration).String() + " moved since the wip branch was merged")
+		sayFix("To integrate the new commits, commit your changes and use", "git pull --rebase "+configuration.RemoteName+" "+baseBranch.Name)
+		return
+	}
+
+	if hasUncommittedChanges() {
+		commitMessage, err := doneCommitMessage(configuration)
+		if err != nil {
+			sayError(err.Error())
+			sayFix("To commit with your own commit message, use", configuration.mob("done --commit --message \"<commit-message>\""))
+			return
+		}
+		gitWithoutEmptyStrings("commit", "--cleanup=strip", "--message", commitMessage, configuration.gitHooksOption())
+	}
+
+	if configuration.DonePush {
+		gitWithoutEmptyStrings("push", configuration.gitHooksOption(), configuration.RemoteName, baseBranch.Name)
+	} else if baseBranch.hasUnpushedCommits(configuration) {
+		sayNext("To publish, use", "git push "+configuration.RemoteName+" "+baseBranch.Name)
+	}
+}
+
+func doneCommitMessage(configuration Configuration) (string, error) {
+	generatedCommitMessage := readGitDirFile("MERGE_MSG")
+	if generatedCommitMessage == "" {
+		generatedCommitMessage = readGitDirFile("SQUASH_MSG")
+	}
+
+	if configuration.DoneCommitMessage == "" {
+		if !containsCommitMessageBesidesTrailers(generatedCommitMessage) {
+			return "", errors.New("no commit message was generated")
+		}
+		return generatedCommitMessage, nil
+	}
+
+	commitMessage := configuration.DoneCommitMessage
+	coauthorTrailers := filterCoauthorTrailers(generatedCommitMessage)
+	if len(coauthorTrailers) > 0 {
+		commitMessage += "\n\n" + strings.Join(coauthorTrailers, "\n")
+	}
+	return commitMessage, nil
+}
+
+func containsCommitMessageBesidesTrailers(commitMessage string) bool {
+	for _, line := range strings.Split(commitMessage, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "Co-authored-by: ") {
+			return true
+		}
+	}
+	return false
+}
+
+func readGitDirFile(name string) string {
+	content, err := ioutil.ReadFile(path.Join(gitDir(), name))
+	if err != nil {
+		debugInfo(name + " could not be read: " + err.Error())
+		return ""
+	}
+	return string(content)
+}
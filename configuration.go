@@ -11,6 +11,7 @@ import (
 type Configuration struct {
 	CliName                        string // override with MOB_CLI_NAME
 	RemoteName                     string // override with MOB_REMOTE_NAME
+	WipRemoteName                  string // override with MOB_WIP_REMOTE_NAME
 	WipCommitMessage               string // override with MOB_WIP_COMMIT_MESSAGE
 	GitHooksEnabled                bool   // override with MOB_GIT_HOOKS_ENABLED
 	RequireCommitMessage           bool   // override with MOB_REQUIRE_COMMIT_MESSAGE
@@ -20,13 +21,40 @@ type Configuration struct {
 	NotifyMessage                  string // override with MOB_NOTIFY_MESSAGE
 	NextStay                       bool   // override with MOB_NEXT_STAY
 	StartIncludeUncommittedChanges bool   // override with MOB_START_INCLUDE_UNCOMMITTED_CHANGES variable
+	StartFrom                      string // set with --from
+	StartPushBase                  bool   // override with MOB_START_PUSH_BASE
+	StartCarryBaseCommits          bool   // set with --carry-base-commits
+	StartPick                      bool   // set with --pick
+	StartPickSelection             string // set with mob join <number|name>
+	JoinRegisterOnly               bool   // set with --register-only
+	FullFetch                      bool   // set with --full-fetch
+	BranchAllBases                 bool   // set with --all-bases
+	BranchMine                     bool   // set with --mine
+	BranchJson                     bool   // set with --json
+	CleanRemote                    bool   // set with --remote
+	CleanOlderThan                 string // set with --older-than
+	StartWorktree                  bool   // set with --worktree
+	StartWorktreePath              string // set with --worktree <path>
 	StashName                      string // override with MOB_STASH_NAME
 	FixedBaseBranch                string // override with MOB_FIXED_BASE_BRANCH
+	DefaultBranch                  string // override with MOB_DEFAULT_BRANCH
 	WipBranchQualifier             string // override with MOB_WIP_BRANCH_QUALIFIER
 	WipBranchQualifierSeparator    string // override with MOB_WIP_BRANCH_QUALIFIER_SEPARATOR
 	WipBranchPrefix                string // override with MOB_WIP_BRANCH_PREFIX
+	WipBranchTemplate              string // override with MOB_WIP_BRANCH_TEMPLATE
+	LegacySessionBranch            bool   // override with MOB_LEGACY_SESSION_BRANCH
 	DoneSquash                     string // override with MOB_DONE_SQUASH
 	RetainWipBranch                bool   // override with MOB_RETAIN_WIP_BRANCH
+	BackupRetention                string // override with MOB_BACKUP_RETENTION
+	Lock                           string // override with MOB_LOCK
+	LockDuration                   string // override with MOB_LOCK_DURATION
+	LockSteal                      bool   // set with --steal
+	DoneContinue                   bool   // set with --continue
+	DoneAbort                      bool   // set with --abort
+	DoneCommit                     bool   // set with --commit
+	DoneCommitMessage              string // set with --commit --message
+	DonePush                       bool   // set with --commit --push
+	DryRun                         bool   // set with --dry-run
 	OpenCommand                    string // override with MOB_OPEN_COMMAND
 	Timer                          string // override with MOB_TIMER
 	TimerRoom                      string // override with MOB_TIMER_ROOM
@@ -36,8 +64,12 @@ type Configuration struct {
 	TimerUrl                       string // override with MOB_TIMER_URL
 }
 
-func (c Configuration) wipBranchQualifierSuffix() string {
-	return c.WipBranchQualifierSeparator + c.WipBranchQualifier
+// the remote of the wip branches and the refs below refs/mob/, which defaults to the remote of the base branches
+func (c Configuration) wipRemoteName() string {
+	if c.WipRemoteName != "" {
+		return c.WipRemoteName
+	}
+	return c.RemoteName
 }
 
 func (c Configuration) customWipBranchQualifierConfigured() bool {
@@ -85,6 +117,7 @@ func getDefaultConfiguration() Configuration {
 	return Configuration{
 		CliName:                        "mob",
 		RemoteName:                     "origin",
+		WipRemoteName:                  "",
 		WipCommitMessage:               "mob next [ci-skip] [ci skip] [skip ci]",
 		GitHooksEnabled:                false,
 		VoiceCommand:                   voiceCommand,
@@ -94,11 +127,16 @@ func getDefaultConfiguration() Configuration {
 		NextStay:                       true,
 		RequireCommitMessage:           false,
 		StartIncludeUncommittedChanges: false,
+		StartPushBase:                  false,
 		FixedBaseBranch:                "",
+		DefaultBranch:                  "",
 		WipBranchQualifier:             "",
 		WipBranchQualifierSeparator:    "-",
 		DoneSquash:                     Squash,
 		RetainWipBranch:                false,
+		BackupRetention:                "14d",
+		Lock:                           LockOff,
+		LockDuration:                   "30m",
 		OpenCommand:                    "",
 		Timer:                          "",
 		TimerLocal:                     true,
@@ -106,6 +144,7 @@ func getDefaultConfiguration() Configuration {
 		TimerUser:                      "",
 		TimerUrl:                       "https://timer.mob.sh/",
 		WipBranchPrefix:                "mob/",
+		LegacySessionBranch:            true,
 		StashName:                      "mob-stash-name",
 	}
 }
@@ -142,6 +181,8 @@ func parseUserConfiguration(configuration Configuration, path string) Configurat
 			setUnquotedString(&configuration.CliName, key, value)
 		case "MOB_REMOTE_NAME":
 			setUnquotedString(&configuration.RemoteName, key, value)
+		case "MOB_WIP_REMOTE_NAME":
+			setUnquotedString(&configuration.WipRemoteName, key, value)
 		case "MOB_WIP_COMMIT_MESSAGE":
 			setUnquotedString(&configuration.WipCommitMessage, key, value)
 		case "MOB_GIT_HOOKS_ENABLED":
@@ -160,14 +201,22 @@ func parseUserConfiguration(configuration Configuration, path string) Configurat
 			setBoolean(&configuration.NextStay, key, value)
 		case "MOB_START_INCLUDE_UNCOMMITTED_CHANGES":
 			setBoolean(&configuration.StartIncludeUncommittedChanges, key, value)
+		case "MOB_START_PUSH_BASE":
+			setBoolean(&configuration.StartPushBase, key, value)
 		case "MOB_FIXED_BASE_BRANCH":
 			setUnquotedString(&configuration.FixedBaseBranch, key, value)
+		case "MOB_DEFAULT_BRANCH":
+			setUnquotedString(&configuration.DefaultBranch, key, value)
 		case "MOB_WIP_BRANCH_QUALIFIER":
 			setUnquotedString(&configuration.WipBranchQualifier, key, value)
 		case "MOB_WIP_BRANCH_QUALIFIER_SEPARATOR":
 			setUnquotedString(&configuration.WipBranchQualifierSeparator, key, value)
 		case "MOB_WIP_BRANCH_PREFIX":
 			setUnquotedString(&configuration.WipBranchPrefix, key, value)
+		case "MOB_WIP_BRANCH_TEMPLATE":
+			setWipBranchTemplate(&configuration, key, value)
+		case "MOB_LEGACY_SESSION_BRANCH":
+			setBoolean(&configuration.LegacySessionBranch, key, value)
 		case "MOB_DONE_SQUASH":
 			setMobDoneSquash(&configuration, key, value)
 		case "MOB_RETAIN_WIP_BRANCH":
@@ -188,6 +237,13 @@ func parseUserConfiguration(configuration Configuration, path string) Configurat
 			setUnquotedString(&configuration.TimerUrl, key, value)
 		case "MOB_STASH_NAME":
 			setUnquotedString(&configuration.StashName, key, value)
+		case "MOB_BACKUP_RETENTION":
+			setUnquotedString(&configuration.BackupRetention, key, value)
+		case "MOB_LOCK":
+			setUnquotedString(&configuration.Lock, key, value)
+			configuration.Lock = lockMode(configuration.Lock)
+		case "MOB_LOCK_DURATION":
+			setUnquotedString(&configuration.LockDuration, key, value)
 
 		default:
 			continue
@@ -231,6 +287,8 @@ func parseProjectConfiguration(configuration Configuration, path string) Configu
 			setUnquotedString(&configuration.CliName, key, value)
 		case "MOB_REMOTE_NAME":
 			setUnquotedString(&configuration.RemoteName, key, value)
+		case "MOB_WIP_REMOTE_NAME":
+			setUnquotedString(&configuration.WipRemoteName, key, value)
 		case "MOB_WIP_COMMIT_MESSAGE":
 			setUnquotedString(&configuration.WipCommitMessage, key, value)
 		case "MOB_GIT_HOOKS_ENABLED":
@@ -241,14 +299,22 @@ func parseProjectConfiguration(configuration Configuration, path string) Configu
 			setBoolean(&configuration.NextStay, key, value)
 		case "MOB_START_INCLUDE_UNCOMMITTED_CHANGES":
 			setBoolean(&configuration.StartIncludeUncommittedChanges, key, value)
+		case "MOB_START_PUSH_BASE":
+			setBoolean(&configuration.StartPushBase, key, value)
 		case "MOB_FIXED_BASE_BRANCH":
 			setUnquotedString(&configuration.FixedBaseBranch, key, value)
+		case "MOB_DEFAULT_BRANCH":
+			setUnquotedString(&configuration.DefaultBranch, key, value)
 		case "MOB_WIP_BRANCH_QUALIFIER":
 			setUnquotedString(&configuration.WipBranchQualifier, key, value)
 		case "MOB_WIP_BRANCH_QUALIFIER_SEPARATOR":
 			setUnquotedString(&configuration.WipBranchQualifierSeparator, key, value)
 		case "MOB_WIP_BRANCH_PREFIX":
 			setUnquotedString(&configuration.WipBranchPrefix, key, value)
+		case "MOB_WIP_BRANCH_TEMPLATE":
+			setWipBranchTemplate(&configuration, key, value)
+		case "MOB_LEGACY_SESSION_BRANCH":
+			setBoolean(&configuration.LegacySessionBranch, key, value)
 		case "MOB_DONE_SQUASH":
 			setMobDoneSquash(&configuration, key, value)
 		case "MOB_RETAIN_WIP_BRANCH":
@@ -267,6 +333,13 @@ func parseProjectConfiguration(configuration Configuration, path string) Configu
 			setUnquotedString(&configuration.TimerUrl, key, value)
 		case "MOB_STASH_NAME":
 			setUnquotedString(&configuration.StashName, key, value)
+		case "MOB_BACKUP_RETENTION":
+			setUnquotedString(&configuration.BackupRetention, key, value)
+		case "MOB_LOCK":
+			setUnquotedString(&configuration.Lock, key, value)
+			configuration.Lock = lockMode(configuration.Lock)
+		case "MOB_LOCK_DURATION":
+			setUnquotedString(&configuration.LockDuration, key, value)
 
 		default:
 			continue
@@ -314,6 +387,23 @@ func setMobDoneSquash(configuration *Configuration, key string, value string) {
 	debugInfo("Overwriting " + key + " =" + strconv.FormatBool(boolValue))
 }
 
+func setWipBranchTemplate(configuration *Configuration, key string, value string) {
+	template := configuration.WipBranchTemplate
+	setUnquotedString(&template, key, value)
+	if template == "" || isValidWipBranchTemplate(key, template) {
+		configuration.WipBranchTemplate = template
+	}
+}
+
+func isValidWipBranchTemplate(key string, template string) bool {
+	if err := wipBranchTemplate(template).validate(); err != nil {
+		sayError("ignoring " + key + "=" + template + ", as " + err.Error())
+		sayFix("Use a template with "+templateQualifier+", e.g.,", key+"="+quote("mob/"+templateBase+"/"+templateQualifier))
+		return false
+	}
+	return true
+}
+
 func parseEnvironmentVariables(configuration Configuration) Configuration {
 	setStringFromEnvVariable(&configuration.CliName, "MOB_CLI_NAME")
 	if configuration.CliName != getDefaultConfiguration().CliName {
@@ -328,6 +418,7 @@ func parseEnvironmentVariables(configuration Configuration) Configuration {
 	experimental("MOB_WIP_BRANCH_PREFIX")
 
 	setStringFromEnvVariable(&configuration.RemoteName, "MOB_REMOTE_NAME")
+	setStringFromEnvVariable(&configuration.WipRemoteName, "MOB_WIP_REMOTE_NAME")
 	setStringFromEnvVariable(&configuration.WipCommitMessage, "MOB_WIP_COMMIT_MESSAGE")
 	setBoolFromEnvVariable(&configuration.GitHooksEnabled, "MOB_GIT_HOOKS_ENABLED")
 	setBoolFromEnvVariable(&configuration.RequireCommitMessage, "MOB_REQUIRE_COMMIT_MESSAGE")
@@ -336,17 +427,25 @@ func parseEnvironmentVariables(configuration Configuration) Configuration {
 	setOptionalStringFromEnvVariable(&configuration.NotifyCommand, "MOB_NOTIFY_COMMAND")
 	setStringFromEnvVariable(&configuration.NotifyMessage, "MOB_NOTIFY_MESSAGE")
 	setStringFromEnvVariable(&configuration.FixedBaseBranch, "MOB_FIXED_BASE_BRANCH")
+	setStringFromEnvVariable(&configuration.DefaultBranch, "MOB_DEFAULT_BRANCH")
 	setStringFromEnvVariable(&configuration.WipBranchQualifierSeparator, "MOB_WIP_BRANCH_QUALIFIER_SEPARATOR")
 
 	setStringFromEnvVariable(&configuration.WipBranchQualifier, "MOB_WIP_BRANCH_QUALIFIER")
 	setStringFromEnvVariable(&configuration.WipBranchPrefix, "MOB_WIP_BRANCH_PREFIX")
+	setWipBranchTemplateFromEnvVariable(&configuration, "MOB_WIP_BRANCH_TEMPLATE")
+	setBoolFromEnvVariable(&configuration.LegacySessionBranch, "MOB_LEGACY_SESSION_BRANCH")
 
 	setBoolFromEnvVariable(&configuration.NextStay, "MOB_NEXT_STAY")
 
 	setBoolFromEnvVariable(&configuration.StartIncludeUncommittedChanges, "MOB_START_INCLUDE_UNCOMMITTED_CHANGES")
+	setBoolFromEnvVariable(&configuration.StartPushBase, "MOB_START_PUSH_BASE")
 
 	setDoneSquashFromEnvVariable(&configuration, "MOB_DONE_SQUASH")
 	setBoolFromEnvVariable(&configuration.RetainWipBranch, "MOB_RETAIN_WIP_BRANCH")
+	setStringFromEnvVariable(&configuration.BackupRetention, "MOB_BACKUP_RETENTION")
+	setStringFromEnvVariable(&configuration.Lock, "MOB_LOCK")
+	configuration.Lock = lockMode(configuration.Lock)
+	setStringFromEnvVariable(&configuration.LockDuration, "MOB_LOCK_DURATION")
 
 	setStringFromEnvVariable(&configuration.OpenCommand, "MOB_OPEN_COMMAND")
 
@@ -396,6 +495,14 @@ func setBoolFromEnvVariable(s *bool, key string) {
 	}
 }
 
+func setWipBranchTemplateFromEnvVariable(configuration *Configuration, key string) {
+	value, set := os.LookupEnv(key)
+	if set && value != "" && isValidWipBranchTemplate(key, value) {
+		configuration.WipBranchTemplate = value
+		debugInfo("overriding " + key + "=" + value)
+	}
+}
+
 func setDoneSquashFromEnvVariable(configuration *Configuration, key string) {
 	value, set := os.LookupEnv(key)
 	if !set {
@@ -428,6 +535,7 @@ func experimental(key string) {
 func config(c Configuration) {
 	say("MOB_CLI_NAME" + "=" + quote(c.CliName))
 	say("MOB_REMOTE_NAME" + "=" + quote(c.RemoteName))
+	say("MOB_WIP_REMOTE_NAME" + "=" + quote(c.WipRemoteName))
 	say("MOB_WIP_COMMIT_MESSAGE" + "=" + quote(c.WipCommitMessage))
 	say("MOB_GIT_HOOKS_ENABLED" + "=" + strconv.FormatBool(c.GitHooksEnabled))
 	say("MOB_REQUIRE_COMMIT_MESSAGE" + "=" + strconv.FormatBool(c.RequireCommitMessage))
@@ -437,13 +545,20 @@ func config(c Configuration) {
 	say("MOB_NOTIFY_MESSAGE" + "=" + quote(c.NotifyMessage))
 	say("MOB_NEXT_STAY" + "=" + strconv.FormatBool(c.NextStay))
 	say("MOB_START_INCLUDE_UNCOMMITTED_CHANGES" + "=" + strconv.FormatBool(c.StartIncludeUncommittedChanges))
+	say("MOB_START_PUSH_BASE" + "=" + strconv.FormatBool(c.StartPushBase))
 	say("MOB_STASH_NAME" + "=" + quote(c.StashName))
 	say("MOB_FIXED_BASE_BRANCH" + "=" + quote(c.FixedBaseBranch))
+	say("MOB_DEFAULT_BRANCH" + "=" + quote(c.DefaultBranch))
 	say("MOB_WIP_BRANCH_QUALIFIER" + "=" + quote(c.WipBranchQualifier))
 	say("MOB_WIP_BRANCH_QUALIFIER_SEPARATOR" + "=" + quote(c.WipBranchQualifierSeparator))
 	say("MOB_WIP_BRANCH_PREFIX" + "=" + quote(c.WipBranchPrefix))
+	say("MOB_WIP_BRANCH_TEMPLATE" + "=" + quote(c.WipBranchTemplate))
+	say("MOB_LEGACY_SESSION_BRANCH" + "=" + strconv.FormatBool(c.LegacySessionBranch))
 	say("MOB_DONE_SQUASH" + "=" + string(c.DoneSquash))
 	say("MOB_RETAIN_WIP_BRANCH" + "=" + strconv.FormatBool(c.RetainWipBranch))
+	say("MOB_BACKUP_RETENTION" + "=" + quote(c.BackupRetention))
+	say("MOB_LOCK" + "=" + quote(c.Lock))
+	say("MOB_LOCK_DURATION" + "=" + quote(c.LockDuration))
 	say("MOB_OPEN_COMMAND" + "=" + quote(c.OpenCommand))
 	say("MOB_TIMER" + "=" + quote(c.Timer))
 	say("MOB_TIMER_ROOM" + "=" + quote(c.TimerRoom))
@@ -482,6 +597,20 @@ func parseArgs(args []string, configuration Configuration) (command string, para
 		switch arg {
 		case "--include-uncommitted-changes", "-i":
 			newConfiguration.StartIncludeUncommittedChanges = true
+		case "--push-base":
+			newConfiguration.StartPushBase = true
+		case "--carry-base-commits":
+			newConfiguration.StartCarryBaseCommits = true
+		case "--pick":
+			newConfiguration.StartPick = true
+		case "--register-only":
+			newConfiguration.JoinRegisterOnly = true
+		case "--worktree":
+			newConfiguration.StartWorktree = true
+			if i+1 != len(args) && isWorktreePathArgument(args[i+1]) {
+				newConfiguration.StartWorktreePath = args[i+1]
+				i++ // skip consumed parameter
+			}
 		case "--debug":
 			// ignore this, already parsed
 		case "--stay", "-s":
@@ -493,9 +622,18 @@ func parseArgs(args []string, configuration Configuration) (command string, para
 				newConfiguration.WipBranchQualifier = args[i+1]
 			}
 			i++ // skip consumed parameter
+		case "--from":
+			if i+1 != len(args) {
+				newConfiguration.StartFrom = args[i+1]
+			}
+			i++ // skip consumed parameter
 		case "--message", "-m":
 			if i+1 != len(args) {
-				newConfiguration.WipCommitMessage = args[i+1]
+				if command == "done" || command == "d" {
+					newConfiguration.DoneCommitMessage = args[i+1]
+				} else {
+					newConfiguration.WipCommitMessage = args[i+1]
+				}
 			}
 			i++ // skip consumed parameter
 		case "--squash":
@@ -504,8 +642,37 @@ func parseArgs(args []string, configuration Configuration) (command string, para
 			newConfiguration.DoneSquash = NoSquash
 		case "--squash-wip":
 			newConfiguration.DoneSquash = SquashWip
+		case "--squash-per-turn":
+			newConfiguration.DoneSquash = SquashPerTurn
 		case "--retain":
 			newConfiguration.RetainWipBranch = true
+		case "--continue":
+			newConfiguration.DoneContinue = true
+		case "--abort":
+			newConfiguration.DoneAbort = true
+		case "--commit":
+			newConfiguration.DoneCommit = true
+		case "--push":
+			newConfiguration.DonePush = true
+		case "--dry-run":
+			newConfiguration.DryRun = true
+		case "--steal":
+			newConfiguration.LockSteal = true
+		case "--full-fetch":
+			newConfiguration.FullFetch = true
+		case "--all-bases":
+			newConfiguration.BranchAllBases = true
+		case "--mine":
+			newConfiguration.BranchMine = true
+		case "--json":
+			newConfiguration.BranchJson = true
+		case "--remote":
+			newConfiguration.CleanRemote = true
+		case "--older-than":
+			if i+1 != len(args) {
+				newConfiguration.CleanOlderThan = args[i+1]
+			}
+			i++ // skip consumed parameter
 		default:
 			if i == 1 {
 				command = arg
@@ -518,12 +685,34 @@ func parseArgs(args []string, configuration Configuration) (command string, para
 	return
 }
 
+// the path is optional, so neither options nor the minutes of the timer are taken as path
+func isWorktreePathArgument(arg string) bool {
+	if strings.HasPrefix(arg, "-") {
+		return false
+	}
+	_, err := strconv.Atoi(arg)
+	return err != nil
+}
+
+func lockMode(value string) string {
+	switch value {
+	case "true", LockRefuse:
+		return LockRefuse
+	case LockWarn:
+		return LockWarn
+	default:
+		return LockOff
+	}
+}
+
 func doneSquash(value string) string {
 	switch value {
 	case "false", NoSquash:
 		return NoSquash
 	case SquashWip:
 		return SquashWip
+	case SquashPerTurn:
+		return SquashPerTurn
 	default:
 		return Squash
 	}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// doneState remembers an interrupted 'mob done' so it can be continued or aborted later on
+type doneState struct {
+	BaseBranch         string
+	WipBranch          string
+	BaseCommit         string // local base branch before 'mob done'
+	WipCommit          string // local wip branch before 'mob done'
+	RemoteWipCommit    string // remote wip branch before 'mob done' pushed anything
+	RemoteBaseCommit   string // remote base branch the wip branch was merged onto
+	UncommittedChanges bool   // the last commit of the wip branch holds the changes which were uncommitted before 'mob done'
+	DoneSquash         string
+	RetainWipBranch    bool
+	DoneCommit         bool
+	DoneCommitMessage  string
+	DonePush           bool
+	Coauthors          []Author
+}
+
+func doneStatePath() string {
+	return path.Join(gitDir(), "MOB_DONE")
+}
+
+func captureDoneState(baseBranch Branch, wipBranch Branch, configuration Configuration) doneState {
+	return doneState{
+		BaseBranch:         baseBranch.Name,
+		WipBranch:          wipBranch.Name,
+		BaseCommit:         silentgitignorefailure("rev-parse", "--verify", "refs/heads/"+baseBranch.Name),
+		WipCommit:          silentgitignorefailure("rev-parse", "--verify", "refs/heads/"+wipBranch.Name),
+		RemoteWipCommit:    remoteBranchCommit(wipBranch, configuration),
+		UncommittedChanges: hasUncommittedChanges(),
+		DoneSquash:         configuration.DoneSquash,
+		RetainWipBranch:    configuration.RetainWipBranch,
+		DoneCommit:         configuration.DoneCommit,
+		DoneCommitMessage:  configuration.DoneCommitMessage,
+		DonePush:           configuration.DonePush,
+		Coauthors:          collectCoauthorsOfSession(configuration, baseBranch, wipBranch),
+	}
+}
+
+func remoteBranchCommit(branch Branch, configuration Configuration) string {
+	return silentgitignorefailure("rev-parse", "--verify", "refs/remotes/"+branch.remote(configuration).Name)
+}
+
+func isDoneInProgress() bool {
+	_, err := os.Stat(doneStatePath())
+	return err == nil
+}
+
+func writeDoneState(state doneState) error {
+	content := "BASE_BRANCH=" + state.BaseBranch + "\n" +
+		"WIP_BRANCH=" + state.WipBranch + "\n" +
+		"BASE_COMMIT=" + state.BaseCommit + "\n" +
+		"WIP_COMMIT=" + state.WipCommit + "\n" +
+		"REMOTE_WIP_COMMIT=" + state.RemoteWipCommit + "\n" +
+		"REMOTE_BASE_COMMIT=" + state.RemoteBaseCommit + "\n" +
+		"UNCOMMITTED_CHANGES=" + strconv.FormatBool(state.UncommittedChanges) + "\n" +
+		"DONE_SQUASH=" + state.DoneSquash + "\n" +
+		"RETAIN_WIP_BRANCH=" + strconv.FormatBool(state.RetainWipBranch) + "\n" +
+		"DONE_COMMIT=" + strconv.FormatBool(state.DoneCommit) + "\n" +
+		"DONE_COMMIT_MESSAGE=" + strconv.Quote(state.DoneCommitMessage) + "\n" + // quoted, as it may span several lines
+		"DONE_PUSH=" + strconv.FormatBool(state.DonePush) + "\n"
+	for _, coauthor := range state.Coauthors {
+		content += "COAUTHOR=" + coauthor + "\n"
+	}
+	debugInfo("writing " + doneStatePath())
+	return ioutil.WriteFile(doneStatePath(), []byte(content), 0644)
+}
+
+func readDoneState() (doneState, error) {
+	var state doneState
+	file, err := os.Open(doneStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, errors.New("there is no 'mob done' in progress")
+		}
+		return state, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, "=") {
+			continue
+		}
+		key := line[0:strings.Index(line, "=")]
+		value := strings.TrimPrefix(line, key+"=")
+		switch key {
+		case "BASE_BRANCH":
+			state.BaseBranch = value
+		case "WIP_BRANCH":
+			state.WipBranch = value
+		case "BASE_COMMIT":
+			state.BaseCommit = value
+		case "WIP_COMMIT":
+			state.WipCommit = value
+		case "REMOTE_WIP_COMMIT":
+			state.RemoteWipCommit = value
+		case "REMOTE_BASE_COMMIT":
+			state.RemoteBaseCommit = value
+		case "UNCOMMITTED_CHANGES":
+			state.UncommittedChanges, _ = strconv.ParseBool(value)
+		case "DONE_SQUASH":
+			state.DoneSquash = doneSquash(value)
+		case "RETAIN_WIP_BRANCH":
+			state.RetainWipBranch, _ = strconv.ParseBool(value)
+		case "DONE_COMMIT":
+			state.DoneCommit, _ = strconv.ParseBool(value)
+		case "DONE_COMMIT_MESSAGE":
+			state.DoneCommitMessage, _ = strconv.Unquote(value)
+		case "DONE_PUSH":
+			state.DonePush, _ = strconv.ParseBool(value)
+		case "COAUTHOR":
+			state.Coauthors = append(state.Coauthors, value)
+		}
+	}
+	if state.BaseBranch == "" || state.WipBranch == "" {
+		return state, errors.New("the state of the 'mob done' in progress is broken (" + doneStatePath() + ")")
+	}
+	return state, scanner.Err()
+}
+
+func removeDoneState() {
+	err := os.Remove(doneStatePath())
+	if err != nil && !os.IsNotExist(err) {
+		sayError(err.Error())
+	}
+}
+
+func getUnmergedFiles() string {
+	return silentgit("diff", "--name-only", "--diff-filter=U")
+}
+
+func doneContinue(configuration Configuration) {
+	state, err := readDoneState()
+	if err != nil {
+		sayError(err.Error())
+		return
+	}
+
+	if !isDoneMergePending(state) {
+		sayError("cannot continue; the merge of '" + state.WipBranch + "' into '" + state.BaseBranch + "' is no longer in progress")
+		sayFix("To go back to where you were before 'mob done', use", configuration.mob("done --abort"))
+		return
+	}
+
+	unmergedFiles := getUnmergedFiles()
+	if unmergedFiles != "" {
+		sayError("cannot continue; there are still unresolved merge conflicts")
+		sayInfoIndented(unmergedFiles)
+		sayFix("To mark a conflict as resolved, use", "git add <file>")
+		return
+	}
+
+	configuration.DoneSquash = state.DoneSquash
+	configuration.RetainWipBranch = state.RetainWipBranch
+	configuration.DoneCommit = state.DoneCommit
+	configuration.DoneCommitMessage = state.DoneCommitMessage
+	configuration.DonePush = state.DonePush
+	sayInfo("continuing 'mob done' of wip branch '" + state.WipBranch + "'")
+	removeDoneState()
+	finishDone(configuration, state, state.UncommittedChanges)
+}
+
+// the merge of 'mob done' was neither aborted nor committed in the meantime. 'git merge --squash' writes
+// no MERGE_HEAD, only SQUASH_MSG and the staged changes.
+func isDoneMergePending(state doneState) bool {
+	if !gitCurrentBranch().Is(state.BaseBranch) {
+		return false
+	}
+	if isMergeInProgress() {
+		return true
+	}
+	_, err := os.Stat(path.Join(gitDir(), "SQUASH_MSG"))
+	return err == nil && hasStagedChanges()
+}
+
+func hasStagedChanges() bool {
+	_, _, err := runCommand("git", "diff", "--cached", "--quiet")
+	return err != nil
+}
+
+// undoes the wip commit of the changes which were uncommitted before 'mob done' and keeps its changes staged
+func undoFinalWipCommit() {
+	if !isMergeInProgress() {
+		git("reset", "--soft", "HEAD^")
+		return
+	}
+
+	// after resolving merge conflicts, the merge is still in progress; merge the commit before the final
+	// wip commit instead, or nothing at all if that one is merged already
+	mergeParent := silentgit("rev-parse", "MERGE_HEAD^")
+	if isAncestor(mergeParent, "HEAD") {
+		for _, file := range []string{"MERGE_HEAD", "MERGE_MODE", "MERGE_MSG"} {
+			if err := os.Remove(path.Join(gitDir(), file)); err != nil && !os.IsNotExist(err) {
+				sayError(err.Error())
+			}
+		}
+		return
+	}
+	if err := ioutil.WriteFile(path.Join(gitDir(), "MERGE_HEAD"), []byte(mergeParent+"\n"), 0644); err != nil {
+		sayError(err.Error())
+	}
+}
+
+func doneAbort(configuration Configuration) {
+	state, err := readDoneState()
+	if err != nil {
+		sayError(err.Error())
+		return
+	}
+	if state.BaseCommit == "" || state.WipCommit == "" {
+		sayError("cannot abort; the 'mob done' in progress did not record the commits of '" + state.BaseBranch + "' and '" + state.WipBranch + "' to go back to")
+		sayFix("To cancel the merge, use", "git merge --abort")
+		sayFix("Then remove the state of the 'mob done' in progress with", "rm "+doneStatePath())
+		return
+	}
+
+	sayInfo("aborting 'mob done' of wip branch '" + state.WipBranch + "'")
+	git("reset", "--hard", state.BaseCommit)
+	git("checkout", state.WipBranch)
+	if state.UncommittedChanges {
+		git("reset", "--mixed", state.WipCommit)
+	} else {
+		git("reset", "--hard", state.WipCommit)
+	}
+
+	wipBranch := newBranch(state.WipBranch)
+	currentRemoteWipCommit := remoteBranchCommit(wipBranch, configuration)
+	if state.RemoteWipCommit != "" && currentRemoteWipCommit != state.RemoteWipCommit {
+		gitWithoutEmptyStrings("push", configuration.gitHooksOption(),
+			"--force-with-lease=refs/heads/"+wipBranch.Name+":"+currentRemoteWipCommit,
+			configuration.wipRemoteName(), state.RemoteWipCommit+":refs/heads/"+wipBranch.Name)
+	}
+	removeDoneState()
+	sayInfo("you are back on wip branch '" + state.WipBranch + "' (base branch '" + state.BaseBranch + "')")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// The dry runs print what a command would do without changing anything. They don't fetch,
+// so the plan is based on the remote branches as of the last fetch.
+
+func doneDryRun(configuration Configuration) {
+	sayInfo("dry run: nothing will be changed; the plan is based on the last fetch")
+	if !isMobProgramming(configuration) {
+		sayFix("to start working together, use", configuration.mob("start"))
+		return
+	}
+
+	baseBranch, wipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	if hasUncommittedChanges() {
+		sayInfo("would commit the uncommitted changes as wip commit:")
+		sayInfoIndented(silentgit("status", "--short"))
+	}
+	if !wipBranch.hasRemoteBranch(configuration) {
+		sayInfo("would switch to '" + baseBranch.Name + "' and delete '" + wipBranch.Name + "', as someone else already ended your session")
+		return
+	}
+
+	mergeBase := silentgit("merge-base", wipBranch.Name, baseBranch.remote(configuration).Name)
+	commits := wipBranchCommits(mergeBase, wipBranch.Name)
+	sayInfo("would merge " + strconv.Itoa(len(commits)) + " commit(s) of '" + wipBranch.Name + "' into '" + baseBranch.Name + "' (" + configuration.DoneSquash + "):")
+	sayDoneDryRunCommits(commits, configuration)
+
+	coauthors := prepareCoauthors(append(wipCommitAuthors(baseBranch.remote(configuration).Name, wipBranch.Name), readParticipants(wipBranch)...))
+	if len(coauthors) > 0 {
+		sayInfo("would add the co-authors:")
+		sayInfoIndented(strings.Join(createCoauthorTrailers(coauthors), "\n"))
+	}
+
+	conflicts, files := mergeConflicts(baseBranch.remote(configuration).Name, wipBranch.Name)
+	if conflicts {
+		sayWarning("the merge into '" + baseBranch.Name + "' would run into merge conflicts")
+		if len(files) > 0 {
+			sayInfoIndented(strings.Join(files, "\n"))
+		}
+	} else {
+		sayInfo("the merge into '" + baseBranch.Name + "' would succeed without merge conflicts")
+	}
+
+	if configuration.RetainWipBranch {
+		sayInfo("would keep the wip branches '" + wipBranch.Name + "' and '" + wipBranch.remote(configuration).Name + "'")
+	} else {
+		sayInfo("would delete the wip branches '" + wipBranch.Name + "' and '" + wipBranch.remote(configuration).Name + "'")
+		sayDryRunParticipants(wipBranch)
+	}
+}
+
+func sayDoneDryRunCommits(commits []wipBranchCommit, configuration Configuration) {
+	switch configuration.DoneSquash {
+	case Squash:
+		sayInfo("would squash all commits into the staged changes:")
+		for _, commit := range commits {
+			sayInfoIndented(commit.summary())
+		}
+	case SquashWip:
+		squashedCommits := squashWipCommits(commits, configuration)
+		for i, squashed := range squashedCommits {
+			sayDryRunSquashedCommit(squashed, "keep", i == len(squashedCommits)-1 && configuration.isWipCommitMessage(squashed.Representative.Message))
+		}
+	case SquashPerTurn:
+		for _, squashed := range squashTurns(commits, configuration, loadCoauthorDirectory()) {
+			if configuration.isWipCommitMessage(squashed.Representative.Message) {
+				sayInfoIndented("commit turn of " + squashed.Representative.AuthorName + " (" + strconv.Itoa(len(squashed.Commits)) + " wip commit(s))")
+			} else {
+				sayDryRunSquashedCommit(squashed, "keep", false)
+			}
+		}
+	default:
+		for _, commit := range commits {
+			sayInfoIndented("keep " + commit.summary())
+		}
+	}
+}
+
+func sayDryRunSquashedCommit(squashed squashedCommit, action string, staged bool) {
+	if staged {
+		sayInfoIndented("stage the changes of the final " + strconv.Itoa(len(squashed.Commits)) + " wip commit(s)")
+		return
+	}
+	line := action + " " + squashed.Representative.summary()
+	if len(squashed.Commits) > 1 {
+		line += " (squashing " + strconv.Itoa(len(squashed.Commits)-1) + " wip commit(s) into it)"
+	}
+	sayInfoIndented(line)
+}
+
+func sayDryRunParticipants(wipBranch Branch) {
+	refs := listMobRefs(participantsRefs(wipBranch))
+	if len(refs) > 0 {
+		sayInfo("would delete the participant refs:")
+		sayInfoIndented(strings.Join(refs, "\n"))
+	}
+}
+
+func resetDryRun(configuration Configuration) {
+	sayInfo("dry run: nothing will be changed; the plan is based on the last fetch")
+	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+
+	sayInfo("would switch to '" + currentBaseBranch.Name + "'")
+	if hasLocalBranch(currentWipBranch.Name) {
+		sayInfo("would delete the local wip branch '" + currentWipBranch.Name + "' with " + commitCount(currentBaseBranch.Name, currentWipBranch.Name) + " commit(s)")
+	}
+	if currentWipBranch.hasRemoteBranch(configuration) {
+		remoteWipBranch := currentWipBranch.remote(configuration).Name
+		sayInfo("would delete the remote wip branch '" + remoteWipBranch + "' with " + commitCount(currentBaseBranch.Name, remoteWipBranch) + " commit(s)")
+	}
+	sayDryRunParticipants(currentWipBranch)
+}
+
+func cleanDryRun(configuration Configuration) {
+	sayInfo("dry run: nothing will be changed; the plan is based on the last fetch")
+	currentBranch := gitCurrentBranch()
+	localBranches := gitBranches()
+
+	if currentBranch.isOrphanWipBranch(configuration) {
+		sayInfo("would switch from the orphan wip branch '" + currentBranch.Name + "' to '" + cleanFallbackBranch(currentBranch, localBranches, configuration) + "'")
+	}
+	orphans := 0
+	for _, branch := range localBranches {
+		b := newBranch(branch)
+		if b.isOrphanWipBranch(configuration) {
+			sayInfo("would remove the orphan wip branch '" + b.Name + "'")
+			orphans++
+		}
+	}
+	if orphans == 0 {
+		sayInfo("there are no orphan wip branches to remove")
+	}
+}
+
+func commitCount(from string, to string) string {
+	return silentgitignorefailure("rev-list", "--count", from+".."+to)
+}
+
+// tells whether merging theirs into ours would conflict, and in which files if git can tell
+func mergeConflicts(ours string, theirs string) (bool, []string) {
+	_, output, err := runCommand("git", "merge-tree", "--write-tree", "--name-only", "--no-messages", ours, theirs)
+	if err == nil {
+		return false, nil
+	}
+	if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 1 {
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		return true, lines[1:]
+	}
+
+	debugInfo("git merge-tree --write-tree requires git 2.38, falling back to the legacy git merge-tree")
+	mergeBase := silentgitignorefailure("merge-base", ours, theirs)
+	output = silentgitignorefailure("merge-tree", mergeBase, ours, theirs)
+	return strings.Contains(output, "+<<<<<<< .our"), nil
+}
+
+func (commit wipBranchCommit) summary() string {
+	hash := commit.Hash
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	return hash + " " + strings.SplitN(commit.Message, "\n", 2)[0]
+}
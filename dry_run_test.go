@@ -0,0 +1,92 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDoneDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.DoneSquash = SquashWip
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "manual commit")
+	createFile(t, "file3.txt", "contentIrrelevant")
+	refs := silentgit("for-each-ref")
+
+	doneDryRun(configuration)
+
+	assertOnBranch(t, "mob-session")
+	equals(t, refs, silentgit("for-each-ref"))
+	assertGitStatus(t, GitStatus{"file3.txt": "??"})
+	assertOutputContains(t, output, "would commit the uncommitted changes as wip commit")
+	assertOutputContains(t, output, "manual commit (squashing 1 wip commit(s) into it)")
+	assertOutputContains(t, output, "Co-authored-by: alice <alice@example.com>")
+	assertOutputContains(t, output, "would succeed without merge conflicts")
+	assertOutputContains(t, output, "would delete the wip branches 'mob-session' and 'origin/mob-session'")
+}
+
+func TestDoneDryRunMergeConflict(t *testing.T) {
+	output, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "example.txt", "content")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
+	git("push")
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	git("fetch")
+	doneDryRun(configuration)
+
+	assertOnBranch(t, "mob-session")
+	equals(t, false, isDoneInProgress())
+	assertOutputContains(t, output, "would run into merge conflicts")
+	assertOutputContains(t, output, "example.txt")
+}
+
+func TestMergeConflicts(t *testing.T) {
+	setup(t)
+	createFileAndCommitIt(t, "example.txt", "ours", "ours")
+	git("checkout", "-b", "theirs", "HEAD^")
+	createFileAndCommitIt(t, "example.txt", "theirs", "theirs")
+
+	conflicts, files := mergeConflicts("master", "theirs")
+
+	equals(t, true, conflicts)
+	equals(t, []string{"example.txt"}, files)
+}
+
+func TestResetDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	start(configuration)
+
+	resetDryRun(configuration)
+
+	assertOnBranch(t, "mob-session")
+	assertOutputContains(t, output, "would delete the local wip branch 'mob-session' with 1 commit(s)")
+	assertOutputContains(t, output, "would delete the remote wip branch 'origin/mob-session' with 1 commit(s)")
+}
+
+func TestCleanDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	git("checkout", "-b", "mob/feature1")
+
+	cleanDryRun(configuration)
+
+	assertOnBranch(t, "mob/feature1")
+	assertOutputContains(t, output, "would switch from the orphan wip branch 'mob/feature1' to 'master'")
+	assertOutputContains(t, output, "would remove the orphan wip branch 'mob/feature1'")
+}
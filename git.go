@@ -1,7 +1,10 @@
 package main
 
 import (
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -10,15 +13,106 @@ func getCachedChanges() string {
 }
 
 func fetch(configuration Configuration) {
-	git("fetch", configuration.RemoteName, "--prune")
+	for _, remoteName := range remoteNames(configuration) {
+		git("fetch", remoteName, "--prune")
+	}
+	updateRemoteHead(configuration)
+}
+
+// the remote of the base branches, followed by the remote of the wip branches if it is a different one
+func remoteNames(configuration Configuration) []string {
+	if configuration.wipRemoteName() != configuration.RemoteName {
+		return []string{configuration.RemoteName, configuration.wipRemoteName()}
+	}
+	return []string{configuration.RemoteName}
+}
+
+// fetches only the given branches and the refs below refs/mob/, as a full fetch takes long in repositories with
+// thousands of branches
+func fetchBranches(configuration Configuration, branches ...Branch) {
+	commands, missingRemoteBranches := fetchBranchesCommands(configuration, branches...)
+	for _, remoteBranch := range missingRemoteBranches {
+		// fetching a missing branch fails and does not prune, so remove its remote-tracking branch ourselves
+		silentgitignorefailure("update-ref", "-d", "refs/remotes/"+remoteBranch)
+	}
+	for _, args := range commands {
+		git(args...)
+	}
+	updateRemoteHead(configuration)
+}
+
+// one fetch per remote, and the remote branches which are gone; the refs below refs/mob/ are fetched from the
+// remote of the wip branches
+func fetchBranchesCommands(configuration Configuration, branches ...Branch) (commands [][]string, missingRemoteBranches []string) {
+	mobRefspec := "+" + mobRefsPrefix + "*:" + mobRefsPrefix + "*"
+	if configuration.FullFetch {
+		for _, remoteName := range remoteNames(configuration) {
+			command := []string{"fetch", remoteName, "--prune", "+refs/heads/*:refs/remotes/" + remoteName + "/*"}
+			if remoteName == configuration.wipRemoteName() {
+				command = append(command, mobRefspec)
+			}
+			commands = append(commands, command)
+		}
+		return commands, nil
+	}
+
+	namesByRemote := map[string][]string{}
+	for _, branch := range branches {
+		remoteName := branch.remoteName(configuration)
+		if branch.Name != "" && !stringContains(namesByRemote[remoteName], branch.Name) {
+			namesByRemote[remoteName] = append(namesByRemote[remoteName], branch.Name)
+		}
+	}
+
+	for _, remoteName := range remoteNames(configuration) {
+		var refspecs []string
+		if remoteName == configuration.wipRemoteName() {
+			refspecs = append(refspecs, mobRefspec)
+		}
+		names := namesByRemote[remoteName]
+		remoteBranches := gitLsRemoteBranches(remoteName, names...)
+		for _, name := range names {
+			remoteBranch := remoteName + "/" + name
+			if stringContains(remoteBranches, remoteBranch) {
+				refspecs = append(refspecs, "+refs/heads/"+name+":refs/remotes/"+remoteBranch)
+			} else {
+				missingRemoteBranches = append(missingRemoteBranches, remoteBranch)
+			}
+		}
+		if len(refspecs) > 0 {
+			commands = append(commands, append([]string{"fetch", "--prune", remoteName}, refspecs...))
+		}
+	}
+	return commands, missingRemoteBranches
 }
 
+// the git dir of the current worktree, e.g., .git/worktrees/x in a linked worktree or .git/modules/x in a submodule
 func gitDir() string {
 	return silentgit("rev-parse", "--absolute-git-dir")
 }
 
+// the git dir shared by all worktrees of the repository
+func gitCommonDir() string {
+	commonDir := silentgit("rev-parse", "--git-common-dir")
+	if filepath.IsAbs(commonDir) {
+		return filepath.Clean(commonDir)
+	}
+	// git prints a relative path, e.g., .git, when the current working directory is in the main worktree
+	dir := workingDir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	return filepath.Join(dir, commonDir)
+}
+
+// the top-level directory of the working tree, i.e., of the linked worktree or submodule you are in,
+// or an empty string if there is no working tree, e.g., in a bare repository
 func gitRootDir() string {
-	return strings.TrimSuffix(gitDir(), "/.git")
+	return silentgitignorefailure("rev-parse", "--show-toplevel")
+}
+
+func isLinkedWorktree() bool {
+	return !isSameDir(gitDir(), gitCommonDir())
 }
 
 func gitUserName() string {
@@ -91,7 +185,15 @@ func deleteEmptyStrings(s []string) []string {
 }
 
 func runCommand(name string, args ...string) (string, string, error) {
+	return runCommandWithEnvironment(nil, name, args...)
+}
+
+// environment holds additional KEY=value pairs for the command
+func runCommandWithEnvironment(environment []string, name string, args ...string) (string, string, error) {
 	command := exec.Command(name, args...)
+	if len(environment) > 0 {
+		command.Env = append(os.Environ(), environment...)
+	}
 	if len(workingDir) > 0 {
 		command.Dir = workingDir
 	}
@@ -103,6 +205,11 @@ func runCommand(name string, args ...string) (string, string, error) {
 	return commandString, output, err
 }
 
+func isMergeInProgress() bool {
+	_, err := os.Stat(path.Join(gitDir(), "MERGE_HEAD"))
+	return err == nil
+}
+
 func isNothingToCommit() bool {
 	output := silentgit("status", "--short")
 	return len(output) == 0
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The optional lock makes sure only one person types at a time. It is a lease stored in the remote
+// ref refs/mob/lock/<wip-branch>, taken by 'mob start' and released by 'mob next' and 'mob done'.
+const lockRefPrefix = mobRefsPrefix + "lock/"
+
+const (
+	LockOff    = "off"
+	LockWarn   = "warn"
+	LockRefuse = "refuse"
+)
+
+type sessionLock struct {
+	Holder  Author
+	Expires time.Time
+	Commit  string
+}
+
+func lockRef(wipBranch Branch) string {
+	return lockRefPrefix + wipBranch.Name
+}
+
+func (lock sessionLock) record() string {
+	return "holder=" + lock.Holder + "\n" + "expires=" + lock.Expires.UTC().Format(time.RFC3339) + "\n"
+}
+
+func (lock sessionLock) isFresh(now time.Time) bool {
+	return now.Before(lock.Expires)
+}
+
+func parseSessionLock(record string) sessionLock {
+	var lock sessionLock
+	for _, line := range strings.Split(record, "\n") {
+		if strings.HasPrefix(line, "holder=") {
+			lock.Holder = strings.TrimPrefix(line, "holder=")
+		} else if strings.HasPrefix(line, "expires=") {
+			lock.Expires, _ = time.Parse(time.RFC3339, strings.TrimPrefix(line, "expires="))
+		}
+	}
+	return lock
+}
+
+// reads the lock as of the last fetch
+func readSessionLock(wipBranch Branch) (sessionLock, bool) {
+	commit := silentgitignorefailure("rev-parse", "--verify", lockRef(wipBranch))
+	if commit == "" {
+		return sessionLock{}, false
+	}
+	lock := parseSessionLock(readMobRecord(lockRef(wipBranch)))
+	lock.Commit = commit
+	return lock, true
+}
+
+func acquireLock(configuration Configuration, wipBranch Branch) error {
+	if configuration.Lock == LockOff {
+		return nil
+	}
+	duration, err := parseAge(configuration.LockDuration)
+	if err != nil {
+		sayWarning("Skipped taking the lock, because MOB_LOCK_DURATION is not a valid age (" + configuration.LockDuration + ")")
+		return nil
+	}
+
+	fetchMobRefs(configuration, lockRefPrefix)
+	me := gitUserIdentity()
+	lock, locked := readSessionLock(wipBranch)
+	if locked && lock.Holder != me && lock.isFresh(time.Now()) {
+		minutes := strconv.Itoa(int(time.Until(lock.Expires).Minutes()) + 1)
+		if configuration.LockSteal {
+			sayWarning("taking over the lock of " + lock.Holder + " on '" + wipBranch.Name + "'")
+		} else if configuration.Lock == LockWarn {
+			sayWarning(lock.Holder + " is typing on '" + wipBranch.Name + "' (their lock expires in " + minutes + " minutes)")
+			return nil
+		} else {
+			sayError("cannot start; " + lock.Holder + " is typing on '" + wipBranch.Name + "' (their lock expires in " + minutes + " minutes)")
+			sayFix("To hand over, ask them to use", configuration.mob("next"))
+			sayFix("To take over anyway, use", configuration.mob("start --steal"))
+			return errors.New("cannot start; the session is locked")
+		}
+	}
+
+	newLock := sessionLock{Holder: me, Expires: time.Now().Add(duration)}
+	commit := createMobRecord(newLock.record())
+	commandString, output, err := runCommand("git", deleteEmptyStrings([]string{"push", configuration.gitHooksOption(),
+		"--force-with-lease=" + lockRef(wipBranch) + ":" + lock.Commit, configuration.wipRemoteName(), commit + ":" + lockRef(wipBranch)})...)
+	if err != nil {
+		debugInfo(output)
+		sayError("cannot start; someone else took the lock on '" + wipBranch.Name + "' in the meantime")
+		sayFix("To try again, use", configuration.mob("start"))
+		return errors.New("cannot start; the session is locked")
+	}
+	sayIndented(commandString)
+	silentgit("update-ref", lockRef(wipBranch), commit)
+	return nil
+}
+
+// releases the lock if you hold it
+func releaseLock(configuration Configuration, wipBranch Branch) {
+	if configuration.Lock == LockOff {
+		return
+	}
+	fetchMobRefs(configuration, lockRefPrefix)
+	lock, locked := readSessionLock(wipBranch)
+	if !locked || lock.Holder != gitUserIdentity() {
+		return
+	}
+	deleteLock(configuration, wipBranch, lock)
+}
+
+// removes the lock no matter who holds it, as the session ends
+func removeLock(configuration Configuration, wipBranch Branch) {
+	if configuration.Lock == LockOff {
+		return
+	}
+	fetchMobRefs(configuration, lockRefPrefix)
+	lock, locked := readSessionLock(wipBranch)
+	if locked {
+		deleteLock(configuration, wipBranch, lock)
+	}
+}
+
+func deleteLock(configuration Configuration, wipBranch Branch, lock sessionLock) {
+	commandString, output, err := runCommand("git", deleteEmptyStrings([]string{"push", configuration.gitHooksOption(),
+		"--force-with-lease=" + lockRef(wipBranch) + ":" + lock.Commit, configuration.wipRemoteName(), "--delete", lockRef(wipBranch)})...)
+	if err != nil {
+		debugInfo(output)
+		sayWarning("Skipped releasing the lock on '" + wipBranch.Name + "', because it changed in the meantime")
+		return
+	}
+	sayIndented(commandString)
+	silentgit("update-ref", "-d", lockRef(wipBranch))
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestStartRefusesWhenSomeoneElseHoldsTheLock(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.Lock = LockRefuse
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	err := start(configuration)
+
+	equals(t, true, err != nil)
+	assertOnBranch(t, "master")
+	assertOutputContains(t, output, "cannot start; localother <localother@example.com> is typing on 'mob-session'")
+	assertOutputContains(t, output, "mob start --steal")
+}
+
+func TestStartWarnsWhenSomeoneElseHoldsTheLock(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.Lock = LockWarn
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	err := start(configuration)
+
+	equals(t, nil, err)
+	assertOnBranch(t, "mob-session")
+	assertOutputContains(t, output, "localother <localother@example.com> is typing on 'mob-session'")
+	lock, _ := readSessionLock(newBranch("mob-session"))
+	equals(t, "localother <localother@example.com>", lock.Holder)
+}
+
+func TestStartStealsTheLock(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.Lock = LockRefuse
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	configuration.LockSteal = true
+	err := start(configuration)
+
+	equals(t, nil, err)
+	assertOnBranch(t, "mob-session")
+	lock, _ := readSessionLock(newBranch("mob-session"))
+	equals(t, "local <local@example.com>", lock.Holder)
+}
+
+func TestNextReleasesTheLock(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.Lock = LockRefuse
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	err := start(configuration)
+
+	equals(t, nil, err)
+	assertOnBranch(t, "mob-session")
+}
+
+func TestDoneRemovesTheLock(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.Lock = LockRefuse
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+
+	done(configuration)
+
+	equals(t, "", silentgit("ls-remote", configuration.RemoteName, lockRefPrefix+"*"))
+}
+
+func TestStartReleasesTheLockWhenPushingTheWipBranchFails(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.Lock = LockRefuse
+	rejectBranchPushes(t)
+
+	err := start(configuration)
+
+	equals(t, true, err != nil)
+	assertOutputContains(t, output, "hook declined")
+	equals(t, "", silentgit("ls-remote", configuration.RemoteName, lockRefPrefix+"*"))
+	_, locked := readSessionLock(newBranch("mob-session"))
+	equals(t, false, locked)
+}
+
+func TestStartInWorktreeReleasesTheLockWhenPushingTheWipBranchFails(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.Lock = LockRefuse
+	configuration.StartWorktree = true
+	rejectBranchPushes(t)
+
+	err := start(configuration)
+
+	equals(t, true, err != nil)
+	equals(t, "", silentgit("ls-remote", configuration.RemoteName, lockRefPrefix+"*"))
+}
+
+func TestStartReleasesTheLockWhenRegisteringTheParticipantFails(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.Lock = LockRefuse
+	rejectPushesTo(t, participantsRefPrefix+"*")
+
+	err := start(configuration)
+
+	equals(t, true, err != nil)
+	equals(t, "", silentgit("ls-remote", configuration.RemoteName, lockRefPrefix+"*"))
+}
+
+func TestExpiredLockIsTakenOver(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.Lock = LockRefuse
+	expired := sessionLock{Holder: "alice <alice@example.com>", Expires: time.Now().Add(-time.Minute)}
+	pushMobRecord(configuration, lockRef(newBranch("mob-session")), expired.record())
+
+	err := start(configuration)
+
+	equals(t, nil, err)
+	lock, _ := readSessionLock(newBranch("mob-session"))
+	equals(t, "local <local@example.com>", lock.Holder)
+}
+
+func TestParseSessionLock(t *testing.T) {
+	expires := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	lock := parseSessionLock(sessionLock{Holder: "alice <alice@example.com>", Expires: expires}.record())
+
+	equals(t, "alice <alice@example.com>", lock.Holder)
+	equals(t, true, expires.Equal(lock.Expires))
+	equals(t, false, lock.isFresh(expires))
+}
+
+// installs a hook in the remote which rejects pushes to branches, but accepts the refs below refs/mob/
+func rejectBranchPushes(t *testing.T) {
+	rejectPushesTo(t, "refs/heads/*")
+}
+
+// installs a hook in the remote which rejects every push to a ref matching the pattern
+func rejectPushesTo(t *testing.T, pattern string) {
+	hook := "#!/bin/sh\nwhile read old new ref; do\n  case $ref in " + pattern + ") exit 1;; esac\ndone\n"
+	if err := ioutil.WriteFile(tempDir+"/remote/hooks/pre-receive", []byte(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
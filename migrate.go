@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// 'mob migrate' renames the legacy wip branch mob-session of the base branch master to the current naming scheme,
+// e.g., mob/master, together with its session metadata and participants. Afterwards, MOB_LEGACY_SESSION_BRANCH=false
+// turns off the special handling of mob-session and master.
+
+func migrate(configuration Configuration) error {
+	legacyBranch := newBranch("mob-session")
+	targetBranch := migrationTarget(configuration)
+
+	fetchBranches(configuration, legacyBranch, targetBranch)
+	hasLocalLegacyBranch := hasLocalBranch(legacyBranch.Name)
+	hasRemoteLegacyBranch := legacyBranch.hasRemoteBranch(configuration)
+
+	if !hasLocalLegacyBranch && !hasRemoteLegacyBranch {
+		sayInfo("there is no legacy wip branch '" + legacyBranch.Name + "' to migrate")
+		sayLegacySessionBranchFix(configuration, targetBranch)
+		return nil
+	}
+	if hasLocalBranch(targetBranch.Name) || targetBranch.hasRemoteBranch(configuration) {
+		sayError("cannot migrate; the wip branch '" + targetBranch.Name + "' already exists")
+		sayFix("To finish that session first, use", "git checkout "+targetBranch.Name+" && "+configuration.mob("done"))
+		return errors.New("cannot migrate; the wip branch already exists")
+	}
+
+	removeLock(configuration, legacyBranch)
+	refsToMove := legacyMobRefs(legacyBranch, targetBranch)
+	var refspecs []string
+	if hasRemoteLegacyBranch {
+		refspecs = append(refspecs, "refs/remotes/"+legacyBranch.remote(configuration).Name+":refs/heads/"+targetBranch.Name, ":refs/heads/"+legacyBranch.Name)
+	}
+	for _, from := range sortedKeys(refsToMove) {
+		refspecs = append(refspecs, from+":"+refsToMove[from], ":"+from)
+	}
+	if len(refspecs) > 0 {
+		gitWithoutEmptyStrings(append([]string{"push", configuration.gitHooksOption(), "--atomic", configuration.wipRemoteName()}, refspecs...)...)
+	}
+	for from, to := range refsToMove {
+		silentgit("update-ref", to, from)
+		silentgit("update-ref", "-d", from)
+	}
+	fetchBranches(configuration, legacyBranch, targetBranch)
+
+	if hasLocalLegacyBranch {
+		git("branch", "--move", legacyBranch.Name, targetBranch.Name)
+		if targetBranch.hasRemoteBranch(configuration) {
+			git("branch", "--set-upstream-to="+targetBranch.remote(configuration).Name, targetBranch.Name)
+		}
+	}
+
+	sayInfo("migrated the legacy wip branch '" + legacyBranch.Name + "' to '" + targetBranch.Name + "'")
+	sayLegacySessionBranchFix(configuration, targetBranch)
+	return nil
+}
+
+// the wip branch of master without the legacy handling
+func migrationTarget(configuration Configuration) Branch {
+	modern := configuration
+	modern.LegacySessionBranch = false
+	modern.WipBranchQualifier = ""
+	return getWipBranch(newBranch("master"), modern)
+}
+
+// the session metadata and the participants of the legacy wip branch, mapped to their refs for the target branch
+func legacyMobRefs(legacyBranch Branch, targetBranch Branch) map[string]string {
+	refs := map[string]string{}
+	if _, recorded := readSessionMeta(legacyBranch); recorded {
+		refs[metaRef(legacyBranch)] = metaRef(targetBranch)
+	}
+	for _, ref := range listMobRefs(participantsRefs(legacyBranch)) {
+		refs[ref] = participantsRefs(targetBranch) + strings.TrimPrefix(ref, participantsRefs(legacyBranch))
+	}
+	return refs
+}
+
+func sortedKeys(refs map[string]string) []string {
+	var keys []string
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sayLegacySessionBranchFix(configuration Configuration, targetBranch Branch) {
+	if !configuration.LegacySessionBranch {
+		return
+	}
+	sayFix("To use '"+targetBranch.Name+"' instead of 'mob-session' on master from now on, add this line to the .mob file of your project", "MOB_LEGACY_SESSION_BRANCH=false")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMigrateRenamesLegacySession(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	err := migrate(configuration)
+
+	equals(t, nil, err)
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	equals(t, true, hasLocalBranch("mob/master"))
+	equals(t, true, newBranch("mob/master").hasRemoteBranch(configuration))
+	meta, recorded := readSessionMeta(newBranch("mob/master"))
+	equals(t, true, recorded)
+	equals(t, "master", meta.BaseBranch)
+	equals(t, []Author{"local <local@example.com>"}, readParticipants(newBranch("mob/master")))
+	assertOutputContains(t, output, "MOB_LEGACY_SESSION_BRANCH=false")
+
+	setWorkingDir(tempDir + "/localother")
+	configuration.LegacySessionBranch = false
+	start(configuration)
+	assertOnBranch(t, "mob/master")
+	assertFileExist(t, "file1.txt")
+}
+
+func TestMigrateWithoutLegacySession(t *testing.T) {
+	output, configuration := setup(t)
+
+	err := migrate(configuration)
+
+	equals(t, nil, err)
+	assertOutputContains(t, output, "there is no legacy wip branch 'mob-session' to migrate")
+}
+
+func TestMigrateRefusesWhenTargetExists(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	setWorkingDir(tempDir + "/localother")
+	modern := configuration
+	modern.LegacySessionBranch = false
+	start(modern)
+
+	setWorkingDir(tempDir + "/local")
+	err := migrate(configuration)
+
+	equals(t, true, err != nil)
+	assertOutputContains(t, output, "cannot migrate; the wip branch 'mob/master' already exists")
+	equals(t, true, newBranch("mob-session").hasRemoteBranch(configuration))
+}
+
+func TestDetermineBranchesWithoutLegacySessionBranch(t *testing.T) {
+	configuration := getDefaultConfiguration()
+	configuration.LegacySessionBranch = false
+
+	baseBranch, wipBranch := determineBranches(newBranch("master"), []string{"master"}, configuration)
+
+	equals(t, "master", baseBranch.Name)
+	equals(t, "mob/master", wipBranch.Name)
+	equals(t, false, newBranch("mob-session").IsWipBranch(configuration))
+}
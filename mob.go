@@ -22,9 +22,10 @@ var (
 )
 
 const (
-	Squash    = "squash"
-	NoSquash  = "no-squash"
-	SquashWip = "squash-wip"
+	Squash        = "squash"
+	NoSquash      = "no-squash"
+	SquashWip     = "squash-wip"
+	SquashPerTurn = "squash-per-turn"
 )
 
 func main() {
@@ -37,8 +38,8 @@ func main() {
 	currentUser, _ := user.Current()
 	userConfigurationPath := currentUser.HomeDir + "/.mob"
 	configuration = parseUserConfiguration(configuration, userConfigurationPath)
-	if isGit() {
-		configuration = parseProjectConfiguration(configuration, gitRootDir()+"/.mob")
+	if rootDir := gitRootDir(); rootDir != "" {
+		configuration = parseProjectConfiguration(configuration, rootDir+"/.mob")
 	}
 	debugInfo("Args '" + strings.Join(os.Args, " ") + "'")
 	currentCliName := currentCliName(os.Args[0])
@@ -67,7 +68,23 @@ func currentCliName(argZero string) string {
 func execute(command string, parameter []string, configuration Configuration) {
 
 	switch command {
-	case "s", "start":
+	case "s", "start", "join", "join-session":
+		if command == "join-session" {
+			// same as 'join --register-only'
+			command = "join"
+			configuration.JoinRegisterOnly = true
+		}
+		if command == "join" {
+			if len(parameter) > 0 {
+				configuration.StartPickSelection = parameter[0]
+				parameter = parameter[1:]
+			}
+			if configuration.JoinRegisterOnly {
+				joinSession(configuration)
+				return
+			}
+			configuration.StartPick = true
+		}
 		err := start(configuration)
 		if !isMobProgramming(configuration) || err != nil {
 			return
@@ -85,13 +102,37 @@ func execute(command string, parameter []string, configuration Configuration) {
 	case "n", "next":
 		next(configuration)
 	case "d", "done":
-		done(configuration)
+		if configuration.DoneContinue {
+			doneContinue(configuration)
+		} else if configuration.DoneAbort {
+			doneAbort(configuration)
+		} else if configuration.DryRun {
+			doneDryRun(configuration)
+		} else {
+			done(configuration)
+		}
 	case "fetch":
 		fetch(configuration)
+	case "restore":
+		restore(configuration, parameter)
+	case "migrate":
+		migrate(configuration)
 	case "reset":
-		reset(configuration)
+		if configuration.DryRun {
+			resetDryRun(configuration)
+		} else {
+			reset(configuration)
+		}
 	case "clean":
-		clean(configuration)
+		if configuration.CleanRemote {
+			if err := cleanRemote(configuration); err != nil {
+				exit(1)
+			}
+		} else if configuration.DryRun {
+			cleanDryRun(configuration)
+		} else {
+			clean(configuration)
+		}
 	case "config":
 		config(configuration)
 	case "status":
@@ -113,12 +154,6 @@ func execute(command string, parameter []string, configuration Configuration) {
 		}
 	case "moo":
 		moo(configuration)
-	case "sw", "squash-wip":
-		if len(parameter) > 1 && parameter[0] == "--git-editor" {
-			squashWipGitEditor(parameter[1], configuration)
-		} else if len(parameter) > 1 && parameter[0] == "--git-sequence-editor" {
-			squashWipGitSequenceEditor(parameter[1], configuration)
-		}
 	case "version", "--version", "-v":
 		version()
 	case "help", "--help", "-h":
@@ -129,28 +164,20 @@ func execute(command string, parameter []string, configuration Configuration) {
 }
 
 func clean(configuration Configuration) {
-	git("fetch", configuration.RemoteName)
-
 	currentBranch := gitCurrentBranch()
 	localBranches := gitBranches()
+	fetchBranches(configuration, localWipBranches(localBranches, configuration)...)
 
 	if currentBranch.isOrphanWipBranch(configuration) {
-		currentBaseBranch, _ := determineBranches(currentBranch, localBranches, configuration)
-
 		sayInfo("Current branch " + currentBranch.Name + " is an orphan")
-		if currentBaseBranch.exists(localBranches) {
-			git("checkout", currentBaseBranch.Name)
-		} else if newBranch("main").exists(localBranches) {
-			git("checkout", "main")
-		} else {
-			git("checkout", "master")
-		}
+		git("checkout", cleanFallbackBranch(currentBranch, localBranches, configuration))
 	}
 
 	for _, branch := range localBranches {
 		b := newBranch(branch)
 		if b.isOrphanWipBranch(configuration) {
 			sayInfo("Removing orphan wip branch " + b.Name)
+			backupBranch(configuration, b)
 			git("branch", "-d", b.Name)
 		}
 	}
@@ -187,6 +214,25 @@ func getNotifyCommand(message string, notifyCommand string) string {
 	return injectCommandWithMessage(notifyCommand, message)
 }
 
+func localWipBranches(localBranches []string, configuration Configuration) []Branch {
+	var wipBranches []Branch
+	for _, name := range localBranches {
+		if branch := newBranch(name); branch.IsWipBranch(configuration) {
+			wipBranches = append(wipBranches, branch)
+		}
+	}
+	return wipBranches
+}
+
+// the branch to switch to when leaving an orphan wip branch
+func cleanFallbackBranch(currentBranch Branch, localBranches []string, configuration Configuration) string {
+	currentBaseBranch, _ := determineBranches(currentBranch, localBranches, configuration)
+	if currentBaseBranch.exists(localBranches) {
+		return currentBaseBranch.Name
+	}
+	return defaultBranch(localBranches, configuration).Name
+}
+
 func executeCommandsInBackgroundProcess(commands ...string) (err error) {
 	cmds := make([]string, 0)
 	for _, c := range commands {
@@ -219,22 +265,28 @@ func moo(configuration Configuration) {
 }
 
 func reset(configuration Configuration) {
-	git("fetch", configuration.RemoteName)
-
 	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	fetchBranches(configuration, currentBaseBranch, currentWipBranch)
 
+	if worktree := sessionWorktree(currentWipBranch); worktree != "" {
+		leaveSessionWorktree(worktree, true)
+	}
 	git("checkout", currentBaseBranch.String())
+	backupBranch(configuration, currentWipBranch)
 	if hasLocalBranch(currentWipBranch.String()) {
 		git("branch", "--delete", "--force", currentWipBranch.String())
 	}
 	if currentWipBranch.hasRemoteBranch(configuration) {
-		gitWithoutEmptyStrings("push", configuration.gitHooksOption(), configuration.RemoteName, "--delete", currentWipBranch.String())
+		gitWithoutEmptyStrings("push", configuration.gitHooksOption(), configuration.wipRemoteName(), "--delete", currentWipBranch.String())
 	}
+	removeParticipants(configuration, currentWipBranch)
+	removeLock(configuration, currentWipBranch)
+	removeSessionMeta(configuration, currentWipBranch)
 	sayInfo("Branches " + currentWipBranch.String() + " and " + currentWipBranch.remote(configuration).String() + " deleted")
 }
 
 func start(configuration Configuration) error {
-	uncommittedChanges := hasUncommittedChanges()
+	uncommittedChanges := !configuration.StartWorktree && hasUncommittedChanges()
 	if uncommittedChanges && !configuration.StartIncludeUncommittedChanges {
 		sayInfo("cannot start; clean working tree required")
 		sayUnstagedChangesInfo()
@@ -243,8 +295,21 @@ func start(configuration Configuration) error {
 		return errors.New("cannot start; clean working tree required")
 	}
 
-	git("fetch", configuration.RemoteName, "--prune")
 	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	if configuration.StartPick {
+		picked, err := pickSession(configuration, currentBaseBranch, configuration.StartPickSelection)
+		if err != nil {
+			return err
+		}
+		configuration = picked
+		currentBaseBranch, currentWipBranch = determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	}
+
+	branchesToFetch := []Branch{currentBaseBranch, currentWipBranch}
+	if strings.HasPrefix(configuration.StartFrom, configuration.RemoteName+"/") {
+		branchesToFetch = append(branchesToFetch, newBranch(strings.TrimPrefix(configuration.StartFrom, configuration.RemoteName+"/")))
+	}
+	fetchBranches(configuration, branchesToFetch...)
 
 	if !currentBaseBranch.hasRemoteBranch(configuration) {
 		sayError("Remote branch " + currentBaseBranch.remote(configuration).String() + " is missing")
@@ -252,10 +317,28 @@ func start(configuration Configuration) error {
 		return errors.New("remote branch is missing")
 	}
 
-	if currentBaseBranch.hasUnpushedCommits(configuration) {
-		sayError("cannot start; unpushed changes on base branch must be pushed upstream")
-		sayFix("to fix this, push those commits and try again", "git push "+configuration.RemoteName+" "+currentBaseBranch.String())
-		return errors.New("cannot start; unpushed changes on base branch must be pushed upstream")
+	if configuration.StartFrom != "" && resolveStartRef(configuration.StartFrom) == "" {
+		sayError("cannot start; '" + configuration.StartFrom + "' is not a commit, tag or branch")
+		return errors.New("cannot start; unknown ref to start from")
+	}
+
+	fromGiven := configuration.StartFrom != "" // --carry-base-commits sets it, too
+	if configuration.StartFrom == "" && currentBaseBranch.hasUnpushedCommits(configuration) {
+		if configuration.StartCarryBaseCommits {
+			sayInfo("starting from local base branch '" + currentBaseBranch.String() + "', carrying these commits into the session:")
+			sayUnpushedCommits(currentBaseBranch, configuration)
+			configuration.StartFrom = currentBaseBranch.Name
+		} else if configuration.StartPushBase {
+			sayInfo("pushing these commits of base branch '" + currentBaseBranch.String() + "' first:")
+			sayUnpushedCommits(currentBaseBranch, configuration)
+			gitWithoutEmptyStrings("push", configuration.gitHooksOption(), configuration.RemoteName, currentBaseBranch.Name)
+		} else {
+			sayError("cannot start; unpushed changes on base branch must be pushed upstream")
+			sayFix("to fix this, push those commits and try again", "git push "+configuration.RemoteName+" "+currentBaseBranch.String())
+			sayFix("to let mob push them for you, use", configuration.mob("start --push-base"))
+			sayFix("to keep them in the session instead, use", configuration.mob("start --carry-base-commits"))
+			return errors.New("cannot start; unpushed changes on base branch must be pushed upstream")
+		}
 	}
 
 	if uncommittedChanges && silentgit("ls-tree", "-r", "HEAD", "--full-name", "--name-only", ".") == "" {
@@ -264,27 +347,56 @@ func start(configuration Configuration) error {
 		return errors.New("cannot start; current working dir is an uncommitted subdir")
 	}
 
+	if err := acquireLock(configuration, currentWipBranch); err != nil {
+		return err
+	}
+	// from here on, every failure releases the lock again, so nobody has to wait for it to expire
+	if configuration.StartWorktree {
+		err := startInWorktree(configuration, currentBaseBranch, currentWipBranch)
+		if err != nil {
+			releaseLock(configuration, currentWipBranch)
+		}
+		return err
+	}
+
 	if uncommittedChanges {
-		git("stash", "push", "--include-untracked", "--message", configuration.StashName)
+		if err := gitignorefailure("stash", "push", "--include-untracked", "--message", configuration.StashName); err != nil {
+			releaseLock(configuration, currentWipBranch)
+			return err
+		}
 		sayInfo("uncommitted changes were stashed. If an error occurs later on, you can recover them with 'git stash pop'.")
 	}
 
-	if !isMobProgramming(configuration) {
-		git("merge", "FETCH_HEAD", "--ff-only")
+	if !isMobProgramming(configuration) && configuration.StartFrom == "" {
+		// not FETCH_HEAD, as fetchBranches writes the wip branch, and with a separate wip remote only the wip branch, into it
+		if err := gitignorefailure("merge", currentBaseBranch.remote(configuration).Name, "--ff-only"); err != nil {
+			releaseLock(configuration, currentWipBranch)
+			return err
+		}
 	}
 
+	var err error
 	if currentWipBranch.hasRemoteBranch(configuration) {
-		startJoinMobSession(configuration)
+		if fromGiven {
+			sayWarning("Ignored --from " + configuration.StartFrom + ", because the session on " + currentWipBranch.remote(configuration).String() + " already exists")
+		}
+		err = startJoinMobSession(configuration)
 	} else {
 		warnForActiveWipBranches(configuration, currentBaseBranch)
 
-		startNewMobSession(configuration)
+		err = startNewMobSession(configuration)
 	}
-
-	if uncommittedChanges && configuration.StartIncludeUncommittedChanges {
+	if err == nil {
+		err = registerParticipant(configuration, currentWipBranch)
+	}
+	if err == nil && uncommittedChanges && configuration.StartIncludeUncommittedChanges {
 		stashes := silentgit("stash", "list")
 		stash := findStashByName(stashes, configuration.StashName)
-		git("stash", "pop", stash)
+		err = gitignorefailure("stash", "pop", stash)
+	}
+	if err != nil {
+		releaseLock(configuration, currentWipBranch)
+		return err
 	}
 
 	sayInfo("you are on wip branch '" + currentWipBranch.String() + "' (base branch '" + currentBaseBranch.String() + "')")
@@ -296,7 +408,7 @@ func start(configuration Configuration) error {
 }
 
 func showActiveMobSessions(configuration Configuration, currentBaseBranch Branch) {
-	existingWipBranches := getWipBranchesForBaseBranch(currentBaseBranch, configuration)
+	existingWipBranches := getFetchedWipBranchesForBaseBranch(currentBaseBranch, configuration)
 	if len(existingWipBranches) > 0 {
 		sayInfo("remote wip branches detected:")
 		for _, wipBranch := range existingWipBranches {
@@ -305,20 +417,31 @@ func showActiveMobSessions(configuration Configuration, currentBaseBranch Branch
 	}
 }
 
-func startJoinMobSession(configuration Configuration) {
+func startJoinMobSession(configuration Configuration) error {
 	_, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 
 	sayInfo("joining existing session from " + currentWipBranch.remote(configuration).String())
-	git("checkout", "-B", currentWipBranch.Name, currentWipBranch.remote(configuration).Name)
-	git("branch", "--set-upstream-to="+currentWipBranch.remote(configuration).Name, currentWipBranch.Name)
+	if err := gitignorefailure("checkout", "-B", currentWipBranch.Name, currentWipBranch.remote(configuration).Name); err != nil {
+		return err
+	}
+	return gitignorefailure("branch", "--set-upstream-to="+currentWipBranch.remote(configuration).Name, currentWipBranch.Name)
 }
 
-func startNewMobSession(configuration Configuration) {
+func startNewMobSession(configuration Configuration) error {
 	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 
-	sayInfo("starting new session from " + currentBaseBranch.remote(configuration).String())
-	git("checkout", "-B", currentWipBranch.Name, currentBaseBranch.remote(configuration).Name)
-	gitWithoutEmptyStrings("push", configuration.gitHooksOption(), "--set-upstream", configuration.RemoteName, currentWipBranch.Name)
+	from := currentBaseBranch.remote(configuration).Name
+	if configuration.StartFrom != "" {
+		from = configuration.StartFrom
+	}
+	sayInfo("starting new session from " + from)
+	if err := gitignorefailure("checkout", "-B", currentWipBranch.Name, from); err != nil {
+		return err
+	}
+	if err := gitignorefailure(deleteEmptyStrings([]string{"push", configuration.gitHooksOption(), "--set-upstream", configuration.wipRemoteName(), currentWipBranch.Name})...); err != nil {
+		return err
+	}
+	return recordSessionStart(configuration, currentWipBranch, currentBaseBranch, from)
 }
 
 func next(configuration Configuration) {
@@ -336,84 +459,139 @@ func next(configuration Configuration) {
 
 	if isNothingToCommit() {
 		if currentWipBranch.hasLocalCommits(configuration) {
-			gitWithoutEmptyStrings("push", configuration.gitHooksOption(), configuration.RemoteName, currentWipBranch.Name)
+			if pushWipBranch(configuration, currentWipBranch) != nil {
+				exit(1)
+				return
+			}
 		} else {
 			sayInfo("nothing was done, so nothing to commit")
 		}
 	} else {
 		makeWipCommit(configuration)
-		gitWithoutEmptyStrings("push", configuration.gitHooksOption(), configuration.RemoteName, currentWipBranch.Name)
+		if pushWipBranch(configuration, currentWipBranch) != nil {
+			exit(1)
+			return
+		}
 	}
+	releaseLock(configuration, currentWipBranch)
 	showNext(configuration)
 
-	if !configuration.NextStay {
+	if !configuration.NextStay && currentSessionWorktree(currentWipBranch) == "" {
 		git("checkout", currentBaseBranch.Name)
 	}
 }
 
 func done(configuration Configuration) {
+	if isDoneInProgress() {
+		sayError("cannot run 'mob done'; another 'mob done' is already in progress")
+		sayFix("To finish it after resolving the merge conflicts, use", configuration.mob("done --continue"))
+		sayFix("To go back to where you were before, use", configuration.mob("done --abort"))
+		return
+	}
+
 	if !isMobProgramming(configuration) {
 		sayFix("to start working together, use", configuration.mob("start"))
 		return
 	}
 
+	baseBranch, wipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	worktree := currentSessionWorktree(wipBranch)
+	if worktree != "" && ensureMainWorktreeCanMerge(configuration, baseBranch) != nil {
+		return
+	}
+	state := captureDoneState(baseBranch, wipBranch, configuration)
+
 	if configuration.DoneSquash == SquashWip {
 		squashWip(configuration)
+	} else if configuration.DoneSquash == SquashPerTurn {
+		squashPerTurn(configuration, state.Coauthors)
 	}
 
-	git("fetch", configuration.RemoteName, "--prune")
-
-	baseBranch, wipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	fetchBranches(configuration, baseBranch, wipBranch)
 
 	if wipBranch.hasRemoteBranch(configuration) {
+		if configuration.DoneSquash != SquashWip && configuration.DoneSquash != SquashPerTurn {
+			state.RemoteWipCommit = remoteBranchCommit(wipBranch, configuration)
+		}
 		uncommittedChanges := hasUncommittedChanges()
 		if uncommittedChanges {
 			makeWipCommit(configuration)
 		}
-		gitWithoutEmptyStrings("push", configuration.gitHooksOption(), configuration.RemoteName, wipBranch.Name)
+		state.UncommittedChanges = uncommittedChanges
+		gitWithoutEmptyStrings("push", configuration.gitHooksOption(), configuration.wipRemoteName(), wipBranch.Name)
+		if worktree != "" {
+			leaveSessionWorktree(worktree, false)
+		}
 
 		git("checkout", baseBranch.Name)
 		git("merge", baseBranch.remote(configuration).Name, "--ff-only")
+		state.RemoteBaseCommit = remoteBranchCommit(baseBranch, configuration)
 		mergeFailed := gitignorefailure("merge", squashOrNoCommit(configuration), "--ff", wipBranch.Name)
 		if mergeFailed != nil {
-			// TODO should this be an error and a fix for that error?
+			err := writeDoneState(state)
+			if err != nil {
+				sayError(err.Error())
+			}
 			sayWarning("Skipped deleting " + wipBranch.Name + " because of merge conflicts.")
-			sayWarning("To fix this, solve the merge conflict manually, commit, push, and afterwards delete " + wipBranch.Name)
+			sayFix("To fix this, solve the merge conflict manually, stage the result, and continue with", configuration.mob("done --continue"))
+			sayFix("To go back to where you were before 'mob done', use", configuration.mob("done --abort"))
 			return
 		}
 
-		if !configuration.RetainWipBranch {
-			git("branch", "-D", wipBranch.Name)
+		finishDone(configuration, state, uncommittedChanges)
+	} else {
+		if worktree != "" {
+			if hasUncommittedChanges() {
+				makeWipCommit(configuration)
+			}
+			leaveSessionWorktree(worktree, false)
 		}
+		endSessionEndedBySomeoneElse(configuration, baseBranch, wipBranch, state.RemoteWipCommit)
+	}
+}
 
-		if uncommittedChanges && configuration.DoneSquash != Squash { // give the user the chance to name their final commit
-			git("reset", "--soft", "HEAD^")
-		}
+func finishDone(configuration Configuration, state doneState, uncommittedChanges bool) {
+	wipBranch := newBranch(state.WipBranch)
+	if !configuration.RetainWipBranch {
+		backupBranch(configuration, wipBranch)
+		git("branch", "-D", wipBranch.Name)
+	}
 
-		if !configuration.RetainWipBranch {
-			gitWithoutEmptyStrings("push", configuration.gitHooksOption(), configuration.RemoteName, "--delete", wipBranch.Name)
-		}
+	if uncommittedChanges && configuration.DoneSquash != Squash { // give the user the chance to name their final commit
+		undoFinalWipCommit()
+	}
 
-		cachedChanges := getCachedChanges()
-		hasCachedChanges := len(cachedChanges) > 0
-		if hasCachedChanges {
-			sayInfoIndented(cachedChanges)
-		}
-		err := appendCoauthorsToSquashMsg(gitDir())
-		if err != nil {
-			sayError(err.Error())
-		}
+	if !configuration.RetainWipBranch && wipBranch.hasRemoteBranch(configuration) {
+		gitWithoutEmptyStrings("push", configuration.gitHooksOption(), configuration.wipRemoteName(), "--delete", wipBranch.Name)
+	}
+	if !configuration.RetainWipBranch {
+		removeParticipants(configuration, wipBranch)
+		removeLock(configuration, wipBranch)
+		removeSessionMeta(configuration, wipBranch)
+	} else {
+		releaseLock(configuration, wipBranch)
+	}
 
-		if hasUncommittedChanges() {
-			sayNext("To finish, use", "git commit")
-		} else if configuration.DoneSquash == Squash {
-			sayInfo("nothing was done, so nothing to commit")
-		}
+	cachedChanges := getCachedChanges()
+	hasCachedChanges := len(cachedChanges) > 0
+	if hasCachedChanges {
+		sayInfoIndented(cachedChanges)
+	}
+	coauthors := state.Coauthors
+	if configuration.DoneSquash == SquashPerTurn && !isMergeInProgress() {
+		coauthors = nil // every turn commit already credits the rest of the mob
+	}
+	err := appendCoauthorsToDoneResult(configuration, gitDir(), coauthors, state)
+	if err != nil {
+		sayError(err.Error())
+	}
 
-	} else {
-		git("checkout", baseBranch.Name)
-		git("branch", "-D", wipBranch.Name)
-		sayInfo("someone else already ended your session")
+	if configuration.DoneCommit {
+		commitDone(configuration, newBranch(state.BaseBranch), state.RemoteBaseCommit)
+	} else if hasUncommittedChanges() {
+		sayNext("To finish, use", "git commit")
+	} else if configuration.DoneSquash == Squash {
+		sayInfo("nothing was done, so nothing to commit")
 	}
 }
 
@@ -421,6 +599,12 @@ func status(configuration Configuration) {
 	if isMobProgramming(configuration) {
 		currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 		sayInfo("you are on wip branch " + currentWipBranch.String() + " (base branch " + currentBaseBranch.String() + ")")
+		if meta, recorded := readSessionMeta(currentWipBranch); recorded {
+			sayInfo("the session was started by " + meta.Creator + " at " + meta.CreatedAt.Local().Format("2006-01-02 15:04"))
+			if meta.From != "" && meta.From != currentBaseBranch.remote(configuration).Name {
+				sayInfo("the session was started from '" + meta.From + "'; 'mob done' merges it into '" + currentBaseBranch.String() + "'")
+			}
+		}
 
 		sayLastCommitsList(currentBaseBranch.String(), currentWipBranch.String())
 	} else {
@@ -457,15 +641,22 @@ func showNext(configuration Configuration) {
 	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 	commitsBaseWipBranch := currentBaseBranch.String() + ".." + currentWipBranch.String()
 
-	changes := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%an", "--abbrev-commit")
-	lines := strings.Split(strings.Replace(changes, "\r\n", "\n", -1), "\n")
+	changes := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%aN <%aE>", "--abbrev-commit")
+	coauthorDirectory := loadCoauthorDirectory()
+	var lines []string
+	for _, line := range strings.Split(strings.Replace(changes, "\r\n", "\n", -1), "\n") {
+		if !coauthorDirectory.isExcluded(line) {
+			lines = append(lines, coauthorDirectory.name(line))
+		}
+	}
 	numberOfLines := len(lines)
 	debugInfo("there have been " + strconv.Itoa(numberOfLines) + " changes")
 	debugInfo("current git user.name is '" + gitUserName + "'")
 	if numberOfLines < 1 {
 		return
 	}
-	nextTypist, previousCommitters := findNextTypist(lines, gitUserName)
+	currentTypist := coauthorDirectory.name(gitUserName + " <" + silentgitignorefailure("config", "--get", "user.email") + ">")
+	nextTypist, previousCommitters := findNextTypist(lines, currentTypist)
 	if nextTypist != "" {
 		sayInfo("Committers after your last commit: " + strings.Join(previousCommitters, ", "))
 		sayInfo("***" + nextTypist + "*** is (probably) next.")
@@ -480,11 +671,23 @@ Basic Commands:
   next               handover changes in wip branch to next person
   done               squashes all changes in wip branch to index in base branch
   reset              removes local and remote wip branch
+  join               switches to one of the active sessions of the base branch
+  join-session       same as 'join --register-only'
+  restore            restores the local and remote wip branch from the latest backup
+  migrate            renames the legacy wip branch 'mob-session' to the current naming scheme, e.g., 'mob/master'
 
 Basic Commands(Options):
   start [<minutes>]                      Start a <minutes> timer
     [--include-uncommitted-changes|-i]   Move uncommitted changes to wip branch
     [--branch|-b <branch-postfix>]       Set wip branch to 'mob/<base-branch>/<branch-postfix>'
+    [--steal]                            Take over the lock of someone else (with MOB_LOCK enabled)
+    [--from <commit|tag|branch>]         Start a new session from this ref instead of the remote base branch
+    [--push-base]                        Push unpushed commits of the base branch first
+    [--carry-base-commits]               Start from the local base branch, keeping its unpushed commits in the session
+    [--worktree [<path>]]                Start the session in a linked git worktree, leaving your checkout untouched
+    [--pick]                             Select one of the active sessions of the base branch to join
+  join [<number>|<name>]                 Join the active session with this number or (part of its) name
+    [--register-only]                    Only register as co-author of the session, without switching branches
   next
     [--stay|-s]                          Stay on wip branch (default)
     [--return-to-base-branch|-r]         Return to base branch
@@ -493,10 +696,26 @@ Basic Commands(Options):
     [--no-squash]                        Squash no commits from wip branch, only merge wip branch
     [--squash]                           Squash all commits from wip branch
     [--squash-wip]                       Squash wip commits from wip branch, maintaining manual commits
+    [--squash-per-turn]                  Squash the wip commits of every turn into one commit of its typist, maintaining manual commits
     [--retain]                           Prevent the local and remote wip branches from being deleted
+    [--continue]                         Finish an interrupted done after resolving merge conflicts
+    [--abort]                            Go back to the state before an interrupted done
+    [--commit]                           Commit the changes with the generated commit message
+      [--message|-m <commit-message>]    Use this commit message instead (co-authors are kept)
+      [--push]                           Push the base branch afterwards
+    [--dry-run]                          Print what done would do without changing anything
   reset
     [--branch|-b <branch-postfix>]       Set wip branch to 'mob/<base-branch>/<branch-postfix>'
+    [--dry-run]                          Print what reset would do without changing anything
   clean                                  Removes all orphan wip branches
+    [--dry-run]                          Print what clean would do without changing anything
+    [--remote --older-than <age>]        Delete the wip branches on the remote without commits for longer than <age>, e.g., 30d
+  restore [<branch>|<backup>]            Restore a wip branch from its latest backup or from the given backup
+    [--list]                             List all backups
+  branch                                 List the wip branches of the base branch with the state of their sessions
+    [--all-bases]                        List the wip branches of all base branches
+    [--mine]                             List only the sessions you started, joined or committed to
+    [--json]                             Print the list as JSON
 
 Timer Commands:
   timer <minutes>    start a <minutes> timer
@@ -506,7 +725,7 @@ Timer Commands:
 Get more information:
   status             show the status of the current session
   fetch              fetch remote state
-  branch             show remote wip branches
+  branch             show remote wip branches and their sessions
   config             show all configuration options
   version            show the version
   help               show help
@@ -515,6 +734,7 @@ Other
   moo                moo!
 
 Add --debug to any option to enable verbose logging
+Add --full-fetch to start, done, reset or clean to fetch all branches instead of only those of the session
 `
 	say(output)
 }
@@ -54,6 +54,27 @@ func TestParseArgsDoneNoSquash(t *testing.T) {
 	equals(t, NoSquash, configuration.DoneSquash)
 }
 
+func TestParseArgsDoneContinue(t *testing.T) {
+	configuration := getDefaultConfiguration()
+
+	command, parameters, configuration := parseArgs([]string{"mob", "done", "--squash", "--continue"}, configuration)
+
+	equals(t, "done", command)
+	equals(t, "", strings.Join(parameters, ""))
+	equals(t, true, configuration.DoneContinue)
+	equals(t, false, configuration.DoneAbort)
+}
+
+func TestParseArgsDoneAbort(t *testing.T) {
+	configuration := getDefaultConfiguration()
+
+	command, parameters, configuration := parseArgs([]string{"mob", "done", "--abort", "--debug"}, configuration)
+
+	equals(t, "done", command)
+	equals(t, "", strings.Join(parameters, ""))
+	equals(t, true, configuration.DoneAbort)
+}
+
 func TestParseArgsDoneSquash(t *testing.T) {
 	configuration := getDefaultConfiguration()
 	configuration.DoneSquash = NoSquash
@@ -76,6 +97,19 @@ func TestParseArgsMessage(t *testing.T) {
 	equals(t, "ci-skip", configuration.WipCommitMessage)
 }
 
+func TestParseArgsDoneCommit(t *testing.T) {
+	configuration := getDefaultConfiguration()
+
+	command, parameters, configuration := parseArgs([]string{"mob", "done", "--commit", "--message", "final", "--push"}, configuration)
+
+	equals(t, "done", command)
+	equals(t, "", strings.Join(parameters, ""))
+	equals(t, true, configuration.DoneCommit)
+	equals(t, "final", configuration.DoneCommitMessage)
+	equals(t, true, configuration.DonePush)
+	equals(t, getDefaultConfiguration().WipCommitMessage, configuration.WipCommitMessage)
+}
+
 func TestParseArgsRetain(t *testing.T) {
 	configuration := getDefaultConfiguration()
 
@@ -124,56 +158,111 @@ func assertDetermineBranches(t *testing.T, branch string, qualifier string, bran
 	equals(t, newBranch(expectedWip), wipBranch)
 }
 
-func TestRemoveWipPrefix(t *testing.T) {
+func TestParseWipBranch(t *testing.T) {
 	configuration := getDefaultConfiguration()
 	configuration.WipBranchPrefix = "mob/"
-	equals(t, "master-green", newBranch("mob/master-green").removeWipPrefix(configuration).Name)
-	equals(t, "master-green-blue", newBranch("mob/master-green-blue").removeWipPrefix(configuration).Name)
-	equals(t, "main-branch", newBranch("mob/main-branch").removeWipPrefix(configuration).Name)
+	assertParseWipBranch(t, "mob/master-green", []string{"master"}, configuration, "master", "green")
+	assertParseWipBranch(t, "mob/master-green-blue", []string{"master"}, configuration, "master", "green-blue")
+	assertParseWipBranch(t, "mob/main-branch", []string{}, configuration, "main", "branch")
 }
 
-func TestRemoveWipBranchQualifier(t *testing.T) {
-	var configuration Configuration
+func TestParseWipBranchWithBranchQualifier(t *testing.T) {
+	configuration := getDefaultConfiguration()
 
 	configuration.WipBranchQualifierSeparator = "-"
 	configuration.WipBranchQualifier = "green"
-	equals(t, "master", newBranch("master-green").removeWipQualifier([]string{}, configuration).Name)
+	assertParseWipBranch(t, "mob/master-green", []string{}, configuration, "master", "green")
 
 	configuration.WipBranchQualifierSeparator = "-"
 	configuration.WipBranchQualifier = "test-branch"
-	equals(t, "master", newBranch("master-test-branch").removeWipQualifier([]string{}, configuration).Name)
+	assertParseWipBranch(t, "mob/master-test-branch", []string{}, configuration, "master", "test-branch")
 
 	configuration.WipBranchQualifierSeparator = "-"
 	configuration.WipBranchQualifier = "branch"
-	equals(t, "master-test", newBranch("master-test-branch").removeWipQualifier([]string{}, configuration).Name)
+	assertParseWipBranch(t, "mob/master-test-branch", []string{}, configuration, "master-test", "branch")
 
 	configuration.WipBranchQualifierSeparator = "-"
 	configuration.WipBranchQualifier = "branch"
-	equals(t, "master-test", newBranch("master-test-branch").removeWipQualifier([]string{"master-test"}, configuration).Name)
+	assertParseWipBranch(t, "mob/master-test-branch", []string{"master-test"}, configuration, "master-test", "branch")
 
 	configuration.WipBranchQualifierSeparator = "/-/"
 	configuration.WipBranchQualifier = "branch-qualifier"
-	equals(t, "main", newBranch("main/-/branch-qualifier").removeWipQualifier([]string{}, configuration).Name)
+	assertParseWipBranch(t, "mob/main/-/branch-qualifier", []string{}, configuration, "main", "branch-qualifier")
 
 	configuration.WipBranchQualifierSeparator = "-"
 	configuration.WipBranchQualifier = "branchqualifier"
-	equals(t, "main/branchqualifier", newBranch("main/branchqualifier").removeWipQualifier([]string{}, configuration).Name)
+	assertParseWipBranch(t, "mob/main/branchqualifier", []string{}, configuration, "main/branchqualifier", "")
 
 	configuration.WipBranchQualifierSeparator = ""
 	configuration.WipBranchQualifier = "branchqualifier"
-	equals(t, "main", newBranch("mainbranchqualifier").removeWipQualifier([]string{}, configuration).Name)
+	assertParseWipBranch(t, "mob/mainbranchqualifier", []string{}, configuration, "main", "branchqualifier")
 }
 
-func TestRemoveWipBranchQualifierWithoutBranchQualifierSet(t *testing.T) {
-	var configuration Configuration
+func TestParseWipBranchWithoutBranchQualifierSet(t *testing.T) {
+	configuration := getDefaultConfiguration()
 
 	configuration.WipBranchQualifierSeparator = "-"
 	configuration.WipBranchQualifier = ""
-	equals(t, "main", newBranch("main").removeWipQualifier([]string{}, configuration).Name)
+	assertParseWipBranch(t, "mob/main", []string{}, configuration, "main", "")
 
 	configuration.WipBranchQualifierSeparator = "-"
 	configuration.WipBranchQualifier = ""
-	equals(t, "master", newBranch("master-test-branch").removeWipQualifier([]string{}, configuration).Name)
+	assertParseWipBranch(t, "mob/master-test-branch", []string{}, configuration, "master", "test-branch")
+}
+
+func TestParseWipBranchWithTemplate(t *testing.T) {
+	configuration := getDefaultConfiguration()
+	configuration.WipBranchTemplate = "mob/{base}/{qualifier}"
+
+	assertParseWipBranch(t, "mob/feature/x/green", []string{"feature/x"}, configuration, "feature/x", "green")
+	assertParseWipBranch(t, "mob/main", []string{}, configuration, "main", "")
+	equals(t, false, newBranch("pair/main").IsWipBranch(configuration))
+
+	configuration.WipBranchTemplate = "pair/{user}/{qualifier}"
+	configuration.DefaultBranch = "trunk"
+	assertParseWipBranch(t, "pair/alice/green", []string{"main", "trunk"}, configuration, "trunk", "green")
+	equals(t, false, newBranch("mob/main-green").IsWipBranch(configuration))
+}
+
+func TestDetermineBranchesWithTemplate(t *testing.T) {
+	configuration := getDefaultConfiguration()
+	configuration.WipBranchTemplate = "mob/{base}/{qualifier}"
+
+	baseBranch, wipBranch := determineBranches(newBranch("master"), []string{"master"}, configuration)
+	equals(t, "master", baseBranch.Name)
+	equals(t, "mob/master", wipBranch.Name)
+
+	configuration.WipBranchQualifier = "green"
+	baseBranch, wipBranch = determineBranches(newBranch("feature-1"), []string{"feature-1"}, configuration)
+	equals(t, "feature-1", baseBranch.Name)
+	equals(t, "mob/feature-1/green", wipBranch.Name)
+
+	baseBranch, wipBranch = determineBranches(newBranch("mob/feature-1/green"), []string{"feature-1"}, configuration)
+	equals(t, "feature-1", baseBranch.Name)
+	equals(t, "mob/feature-1/green", wipBranch.Name)
+}
+
+func TestWipBranchTemplateGlobs(t *testing.T) {
+	equals(t, "mob/main-*,mob/main", strings.Join(wipBranchTemplate("mob/{base}-{qualifier}").globs("main"), ","))
+	equals(t, "pair/*/*,pair/*", strings.Join(wipBranchTemplate("pair/{user}/{qualifier}").globs("main"), ","))
+}
+
+func TestWipBranchTemplatePatternIsCompiledOnce(t *testing.T) {
+	template := wipBranchTemplate("mob/{base}/{qualifier}")
+
+	first := template.pattern(map[string]string{"base": "main", "qualifier": "green"})
+	second := template.pattern(map[string]string{"qualifier": "green", "base": "main"})
+	other := template.pattern(map[string]string{"base": "main"})
+
+	equals(t, true, first.Regexp == second.Regexp)
+	equals(t, false, first.Regexp == other.Regexp)
+}
+
+func assertParseWipBranch(t *testing.T, wipBranch string, localBranches []string, configuration Configuration, expectedBase string, expectedQualifier string) {
+	baseBranch, qualifier, ok := parseWipBranch(newBranch(wipBranch), localBranches, configuration)
+	equals(t, true, ok)
+	equals(t, expectedBase, baseBranch.Name)
+	equals(t, expectedQualifier, qualifier)
 }
 
 func TestMobRemoteNameEnvironmentVariable(t *testing.T) {
@@ -319,6 +408,20 @@ func TestStatusMobProgramming(t *testing.T) {
 	assertOutputContains(t, output, "you are on wip branch mob-session")
 }
 
+func TestStatusOnBaseBranchWithoutRemote(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	git("checkout", "master")
+	if err := os.Rename(tempDir+"/remote", tempDir+"/moved"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExitCode(t, 0, func() { status(configuration) })
+
+	assertOutputContains(t, output, "you are on base branch 'master'")
+	assertOutputContains(t, output, "remote wip branches detected:\n  - origin/mob-session\n")
+}
+
 func TestStatusWithMoreThan5LinesOfLog(t *testing.T) {
 	output, configuration := setup(t)
 	configuration.NextStay = true
@@ -411,6 +514,33 @@ func TestReadConfigurationFromFileAndSkipBrokenLines(t *testing.T) {
 	equals(t, getDefaultConfiguration().TimerRoom, actualConfiguration.TimerRoom)
 }
 
+func TestReadConfigurationIgnoresWipBranchTemplateWithoutQualifier(t *testing.T) {
+	output, _ := setup(t)
+
+	for _, template := range []string{"mob/{base}", "mob/session"} {
+		createFile(t, ".mob", "\nMOB_WIP_BRANCH_TEMPLATE=\""+template+"\"")
+		actualConfiguration := parseUserConfiguration(getDefaultConfiguration(), tempDir+"/local/.mob")
+		equals(t, "", actualConfiguration.WipBranchTemplate)
+		assertOutputContains(t, output, "ignoring MOB_WIP_BRANCH_TEMPLATE="+template)
+	}
+
+	createFile(t, ".mob", "\nMOB_WIP_BRANCH_TEMPLATE=\"pair/{user}/{qualifier}\"")
+	actualConfiguration := parseUserConfiguration(getDefaultConfiguration(), tempDir+"/local/.mob")
+	equals(t, "pair/{user}/{qualifier}", actualConfiguration.WipBranchTemplate)
+}
+
+func TestParseEnvironmentVariablesIgnoresWipBranchTemplateWithoutQualifier(t *testing.T) {
+	output, _ := setup(t)
+	os.Setenv("MOB_WIP_BRANCH_TEMPLATE", "mob/{base}")
+	defer os.Unsetenv("MOB_WIP_BRANCH_TEMPLATE")
+
+	configuration := parseEnvironmentVariables(getDefaultConfiguration())
+
+	equals(t, "", configuration.WipBranchTemplate)
+	assertOutputContains(t, output, "ignoring MOB_WIP_BRANCH_TEMPLATE=mob/{base}, as it has no {qualifier} placeholder")
+	assertOutputContains(t, output, `MOB_WIP_BRANCH_TEMPLATE="mob/{base}/{qualifier}"`)
+}
+
 func TestSkipIfConfigurationDoesNotExist(t *testing.T) {
 	Debug = true
 	tempDir = t.TempDir()
@@ -638,6 +768,69 @@ func TestCleanMissingBaseBranch(t *testing.T) {
 	assertNoLocalBranch(t, "mob/feature1")
 }
 
+func TestCleanMissingBaseBranchSwitchesToDefaultBranch(t *testing.T) {
+	_, configuration := setup(t)
+	git("checkout", "-b", "trunk")
+	git("push", "origin", "trunk", "--set-upstream")
+	run(t, "git", "--git-dir="+tempDir+"/remote", "symbolic-ref", "HEAD", "refs/heads/trunk")
+	git("checkout", "-b", "mob/feature1")
+
+	clean(configuration)
+
+	assertOnBranch(t, "trunk")
+	assertNoLocalBranch(t, "mob/feature1")
+}
+
+func TestCleanMissingBaseBranchSwitchesToConfiguredDefaultBranch(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DefaultBranch = "develop"
+	git("checkout", "-b", "develop")
+	git("push", "origin", "develop", "--set-upstream")
+	git("checkout", "-b", "mob/feature1")
+
+	clean(configuration)
+
+	assertOnBranch(t, "develop")
+	assertNoLocalBranch(t, "mob/feature1")
+}
+
+func TestDefaultBranchFromRemoteHead(t *testing.T) {
+	_, configuration := setup(t)
+	git("checkout", "-b", "trunk")
+	git("push", "origin", "trunk", "--set-upstream")
+	git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/trunk")
+
+	equals(t, newBranch("trunk"), defaultBranch(gitBranches(), configuration))
+}
+
+func TestDefaultBranchFromRemoteHeadAfterFetch(t *testing.T) {
+	_, configuration := setup(t)
+	git("checkout", "-b", "trunk")
+	git("push", "origin", "trunk", "--set-upstream")
+	run(t, "git", "--git-dir="+tempDir+"/remote", "symbolic-ref", "HEAD", "refs/heads/trunk")
+
+	fetchBranches(configuration, newBranch("trunk"))
+
+	equals(t, newBranch("trunk"), defaultBranch([]string{"master", "main"}, configuration))
+	equals(t, "refs/remotes/origin/trunk", silentgit("symbolic-ref", "refs/remotes/origin/HEAD"))
+}
+
+func TestDefaultBranchDoesNotAskTheRemote(t *testing.T) {
+	_, configuration := setup(t)
+	run(t, "git", "--git-dir="+tempDir+"/remote", "symbolic-ref", "HEAD", "refs/heads/trunk")
+
+	equals(t, newBranch("main"), defaultBranch([]string{"master", "main"}, configuration))
+	equals(t, "", silentgitignorefailure("symbolic-ref", "--quiet", "refs/remotes/origin/HEAD"))
+}
+
+func TestDefaultBranchWithoutRemote(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.RemoteName = "unknown"
+
+	equals(t, newBranch("main"), defaultBranch([]string{"master", "main"}, configuration))
+	equals(t, newBranch("master"), defaultBranch([]string{"master"}, configuration))
+}
+
 func TestStartUnstagedChanges(t *testing.T) {
 	output, configuration := setup(t)
 	configuration.StartIncludeUncommittedChanges = false
@@ -685,6 +878,47 @@ func TestStartHasUnpushedCommits(t *testing.T) {
 	assertOutputContains(t, output, "unpushed commits")
 }
 
+func TestStartPushBase(t *testing.T) {
+	output, configuration := setup(t)
+	createFileAndCommitIt(t, "test.txt", "contentIrrelevant", "unpushed change")
+	configuration.StartPushBase = true
+
+	start(configuration)
+
+	assertOnBranch(t, "mob-session")
+	assertOutputContains(t, output, "pushing these commits of base branch 'master' first:")
+	assertOutputContains(t, output, "unpushed change")
+	equals(t, silentgit("rev-parse", "master"), silentgit("rev-parse", "origin/master"))
+	equals(t, silentgit("rev-parse", "master"), silentgit("rev-parse", "origin/mob-session"))
+}
+
+func TestStartCarryBaseCommits(t *testing.T) {
+	output, configuration := setup(t)
+	createFileAndCommitIt(t, "test.txt", "contentIrrelevant", "unpushed change")
+	configuration.StartCarryBaseCommits = true
+
+	start(configuration)
+
+	assertOnBranch(t, "mob-session")
+	assertOutputContains(t, output, "starting from local base branch 'master', carrying these commits into the session:")
+	equals(t, "1", silentgit("rev-list", "--count", "origin/master..master"))
+	equals(t, silentgit("rev-parse", "master"), silentgit("rev-parse", "origin/mob-session"))
+}
+
+func TestStartCarryBaseCommitsJoiningExistingSession(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+	setWorkingDir(tempDir + "/local")
+	createFileAndCommitIt(t, "test.txt", "contentIrrelevant", "unpushed change")
+	configuration.StartCarryBaseCommits = true
+
+	start(configuration)
+
+	assertOnBranch(t, "mob-session")
+	assertOutputNotContains(t, output, "Ignored --from")
+}
+
 func TestBranch(t *testing.T) {
 	output, configuration := setup(t)
 	start(configuration)
@@ -694,6 +928,66 @@ func TestBranch(t *testing.T) {
 	assertOutputContains(t, output, "\norigin/mob-session\n")
 }
 
+func TestStartFetchesOnlyBaseAndWipBranch(t *testing.T) {
+	_, configuration := setup(t)
+	setWorkingDir(tempDir + "/localother")
+	git("checkout", "-b", "unrelated")
+	git("push", "origin", "unrelated")
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+
+	equals(t, "", silentgitignorefailure("rev-parse", "--verify", "--quiet", "origin/unrelated"))
+	equals(t, true, newBranch("mob-session").hasRemoteBranch(configuration))
+}
+
+func TestStartFullFetch(t *testing.T) {
+	_, configuration := setup(t)
+	setWorkingDir(tempDir + "/localother")
+	git("checkout", "-b", "unrelated")
+	git("push", "origin", "unrelated")
+
+	setWorkingDir(tempDir + "/local")
+	configuration.FullFetch = true
+	start(configuration)
+
+	equals(t, true, newBranch("unrelated").hasRemoteBranch(configuration))
+}
+
+func TestFetchBranchesRemovesBranchDeletedOnRemote(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+	reset(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	fetchBranches(configuration, newBranch("master"), newBranch("mob-session"))
+
+	equals(t, false, newBranch("mob-session").hasRemoteBranch(configuration))
+	equals(t, true, newBranch("master").hasRemoteBranch(configuration))
+}
+
+func TestBranchListsRemoteWipBranchesWithoutFetching(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	branch(configuration)
+
+	assertOutputContains(t, output, "\norigin/mob-session\n")
+	equals(t, false, newBranch("mob-session").hasRemoteBranch(configuration))
+}
+
+func TestParseArgsFullFetch(t *testing.T) {
+	configuration := getDefaultConfiguration()
+
+	_, _, configuration = parseArgs([]string{"mob", "reset", "--full-fetch"}, configuration)
+
+	equals(t, true, configuration.FullFetch)
+}
+
 func TestStartIncludeUntrackedFiles(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.StartIncludeUncommittedChanges = true
@@ -1173,6 +1467,54 @@ func TestGitRootDir(t *testing.T) {
 	equals(t, expectedPath, gitRootDir())
 }
 
+func TestGitRootDirInLinkedWorktree(t *testing.T) {
+	setup(t)
+	git("worktree", "add", "-b", "feature1", tempDir+"/local-feature1")
+	setWorkingDir(tempDir + "/local-feature1")
+
+	expectedPath, _ := filepath.EvalSymlinks(tempDir + "/local-feature1")
+	equals(t, expectedPath, gitRootDir())
+	equals(t, true, isLinkedWorktree())
+	equals(t, true, isSameDir(tempDir+"/local/.git", gitCommonDir()))
+}
+
+func TestGitRootDirInSubmodule(t *testing.T) {
+	setup(t)
+	git("-c", "protocol.file.allow=always", "submodule", "add", tempDir+"/remote", "sub")
+	setWorkingDir(tempDir + "/local/sub")
+
+	expectedPath, _ := filepath.EvalSymlinks(tempDir + "/local/sub")
+	equals(t, expectedPath, gitRootDir())
+	equals(t, false, isLinkedWorktree())
+}
+
+func TestVersionInBareRepository(t *testing.T) {
+	output := captureOutput(t)
+	tempDir = t.TempDir()
+	setWorkingDir(tempDir)
+	run(t, "git", "init", "--bare", "--quiet", tempDir+"/bare")
+	setWorkingDir(tempDir + "/bare")
+	previousArgs := os.Args
+	os.Args = []string{"mob", "version"}
+	defer func() { os.Args = previousArgs }()
+
+	assertExitCode(t, 0, main)
+
+	equals(t, "", gitRootDir())
+	assertOutputContains(t, output, "v"+versionNumber)
+}
+
+func TestProjectConfigurationInLinkedWorktree(t *testing.T) {
+	setup(t)
+	git("worktree", "add", "-b", "feature1", tempDir+"/local-feature1")
+	setWorkingDir(tempDir + "/local-feature1")
+	createFile(t, ".mob", "MOB_TIMER_ROOM=\"worktree\"")
+
+	configuration := parseProjectConfiguration(getDefaultConfiguration(), gitRootDir()+"/.mob")
+
+	equals(t, "worktree", configuration.TimerRoom)
+}
+
 func TestBothCreateNonemptyCommitWithNext(t *testing.T) {
 	_, configuration := setup(t)
 
@@ -1327,7 +1669,159 @@ func TestDoneMergeConflict(t *testing.T) {
 	setWorkingDir(tempDir + "/local")
 	start(configuration)
 	done(configuration)
-	assertOutputContains(t, output, "To fix this, solve the merge conflict manually, commit, push, and afterwards delete mob-session")
+	assertOutputContains(t, output, "Skipped deleting mob-session because of merge conflicts.")
+	assertOutputContains(t, output, "mob done --continue")
+	assertOutputContains(t, output, "mob done --abort")
+	equals(t, true, isDoneInProgress())
+}
+
+func TestDoneMergeConflictContinue(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "example.txt", "content")
+	next(configuration)
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
+	git("push")
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	done(configuration)
+
+	doneContinue(configuration)
+	assertOutputContains(t, output, "cannot continue; there are still unresolved merge conflicts")
+	createFile(t, "example.txt", "resolved")
+	git("add", "example.txt")
+	doneContinue(configuration)
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "M",
+	})
+	equals(t, false, isDoneInProgress())
+}
+
+func TestDoneContinueAfterMergeAbort(t *testing.T) {
+	output, configuration := setup(t)
+	for _, squash := range []string{Squash, NoSquash} {
+		configuration.DoneSquash = squash
+		setWorkingDir(tempDir + "/local")
+		start(configuration)
+		createFile(t, "example-"+squash+".txt", "content")
+		next(configuration)
+		setWorkingDir(tempDir + "/localother")
+		git("pull")
+		createFileAndCommitIt(t, "example-"+squash+".txt", "asdf", "asdf")
+		git("push")
+		setWorkingDir(tempDir + "/local")
+		start(configuration)
+		wipCommitBeforeDone := silentgit("rev-parse", "mob-session")
+		done(configuration)
+		if squash == NoSquash {
+			git("merge", "--abort")
+		} else {
+			git("reset", "--merge") // 'git merge --squash' leaves no merge to abort
+		}
+
+		doneContinue(configuration)
+
+		assertOutputContains(t, output, "cannot continue; the merge of 'mob-session' into 'master' is no longer in progress")
+		equals(t, wipCommitBeforeDone, silentgit("rev-parse", "mob-session"))
+		equals(t, wipCommitBeforeDone, silentgit("rev-parse", "origin/mob-session"))
+		equals(t, true, isDoneInProgress())
+		doneAbort(configuration)
+		assertOnBranch(t, "mob-session")
+		next(configuration)
+	}
+}
+
+func TestDoneMergeConflictContinueWithUncommittedChanges(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = NoSquash
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "example.txt", "content")
+	next(configuration)
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
+	git("push")
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	wipCommitBeforeDone := silentgit("rev-parse", "mob-session")
+	createFile(t, "uncommitted.txt", "contentIrrelevant")
+	done(configuration)
+	createFile(t, "example.txt", "resolved")
+	git("add", "example.txt")
+
+	doneContinue(configuration)
+
+	assertOnBranch(t, "master")
+	equals(t, wipCommitBeforeDone, silentgit("rev-parse", "MERGE_HEAD"))
+	assertGitStatus(t, GitStatus{
+		"example.txt":     "M",
+		"uncommitted.txt": "A",
+	})
+}
+
+func TestDoneMergeConflictAbort(t *testing.T) {
+	_, configuration := setup(t)
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "example.txt", "content")
+	next(configuration)
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
+	git("push")
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "uncommitted.txt", "contentIrrelevant")
+	baseCommitBeforeDone := silentgit("rev-parse", "master")
+	wipCommitBeforeDone := silentgit("rev-parse", "mob-session")
+	done(configuration)
+
+	doneAbort(configuration)
+
+	assertOnBranch(t, "mob-session")
+	equals(t, baseCommitBeforeDone, silentgit("rev-parse", "master"))
+	equals(t, wipCommitBeforeDone, silentgit("rev-parse", "mob-session"))
+	equals(t, wipCommitBeforeDone, silentgit("rev-parse", "origin/mob-session"))
+	assertGitStatus(t, GitStatus{
+		"uncommitted.txt": "??",
+	})
+	equals(t, false, isDoneInProgress())
+}
+
+func TestDoneAbortWithoutRecordedCommits(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "work")
+	wipCommit := silentgit("rev-parse", "HEAD")
+	equals(t, nil, writeDoneState(doneState{BaseBranch: "master", WipBranch: "mob-session"}))
+
+	doneAbort(configuration)
+
+	assertOutputContains(t, output, "cannot abort; the 'mob done' in progress did not record the commits")
+	equals(t, wipCommit, silentgit("rev-parse", "HEAD"))
+	equals(t, true, isDoneInProgress())
+}
+
+func TestDoneWhileDoneInProgress(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "example.txt", "content")
+	next(configuration)
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
+	git("push")
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	done(configuration)
+
+	done(configuration)
+
+	assertOutputContains(t, output, "another 'mob done' is already in progress")
 }
 
 func TestDoneMerge(t *testing.T) {
@@ -1348,6 +1842,93 @@ func TestDoneMerge(t *testing.T) {
 	assertOutputContains(t, output, "  git commit")
 }
 
+func TestDoneCommitAndPush(t *testing.T) {
+	_, configuration := setup(t)
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	configuration.DoneCommit = true
+	configuration.DoneCommitMessage = "finished mob session"
+	configuration.DonePush = true
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertCleanGitStatus(t)
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	equals(t, silentgit("rev-parse", "master"), silentgit("rev-parse", "origin/master"))
+	equals(t, "finished mob session\n\nCo-authored-by: alice <alice@example.com>", lastCommitMessage())
+}
+
+func TestDoneCommitAndPushContinuedAfterMergeConflict(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	createFile(t, "example.txt", "content")
+	next(configuration)
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
+	git("push")
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	commitConfiguration := configuration
+	commitConfiguration.DoneCommit = true
+	commitConfiguration.DoneCommitMessage = "finished mob session\n\nresolved the conflict"
+	commitConfiguration.DonePush = true
+	done(commitConfiguration)
+	createFile(t, "example.txt", "resolved")
+	git("add", "example.txt")
+
+	doneContinue(configuration)
+
+	assertOnBranch(t, "master")
+	assertCleanGitStatus(t)
+	equals(t, silentgit("rev-parse", "master"), silentgit("rev-parse", "origin/master"))
+	equals(t, "finished mob session\n\nresolved the conflict", lastCommitMessage())
+}
+
+func TestDoneCommitWithGeneratedCommitMessage(t *testing.T) {
+	_, configuration := setup(t)
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	configuration.DoneCommit = true
+	done(configuration)
+
+	assertCleanGitStatus(t)
+	assertOutputContains(t, run(t, "git", "log", "-1", "--pretty=format:%B"), "Co-authored-by: alice <alice@example.com>")
+	assertOutputNotContains(t, run(t, "git", "log", "-1", "--pretty=format:%B"), "# automatically added")
+	equals(t, true, newBranch("master").hasUnpushedCommits(configuration))
+}
+
+func TestDoneCommitRefusesWhenBaseBranchMoved(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(configuration)
+	start(configuration)
+	done(configuration)
+	remoteBaseCommit := silentgit("rev-parse", "origin/master")
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "other.txt", "contentIrrelevant", "other")
+	git("push")
+
+	setWorkingDir(tempDir + "/local")
+	configuration.DoneCommit = true
+	commitDone(configuration, newBranch("master"), remoteBaseCommit)
+
+	assertOutputContains(t, output, "cannot commit; origin/master moved since the wip branch was merged")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "A",
+	})
+}
+
 func TestDoneSquashNoChanges(t *testing.T) {
 	output, configuration := setup(t)
 	setWorkingDir(tempDir + "/local")
@@ -1460,6 +2041,32 @@ func setup(t *testing.T) (output *string, configuration Configuration) {
 	return output, configuration
 }
 
+type exitCode int
+
+// runs the function until it returns or calls exit, and asserts the exit code, which is 0 if it returns
+func assertExitCode(t *testing.T, expected int, function func()) {
+	previousExit := exit
+	exit = func(code int) {
+		panic(exitCode(code))
+	}
+	defer func() { exit = previousExit }()
+
+	actual := func() (code int) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				exited, ok := recovered.(exitCode)
+				if !ok {
+					panic(recovered)
+				}
+				code = int(exited)
+			}
+		}()
+		function()
+		return 0
+	}()
+	equals(t, expected, actual)
+}
+
 func captureOutput(t *testing.T) *string {
 	messages := ""
 	printToConsole = func(text string) {
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// Every participant of a session registers with a ref of their own, so navigators who never
+// typed are credited as co-authors, too. Separate refs avoid racing with the typist for the wip branch.
+const participantsRefPrefix = mobRefsPrefix + "participants/"
+
+func participantsRefs(wipBranch Branch) string {
+	return participantsRefPrefix + wipBranch.Name + "/"
+}
+
+func gitUserIdentity() Author {
+	return gitUserName() + " <" + gitUserEmail() + ">"
+}
+
+// pushes the participant ref only if it is new or changed, as of the last fetch
+func registerParticipant(configuration Configuration, wipBranch Branch) error {
+	identity := gitUserIdentity()
+	ref := participantsRefs(wipBranch) + refNameComponent(gitUserEmail())
+	if strings.TrimSpace(readMobRecord(ref)) == identity {
+		debugInfo(identity + " is registered as participant of " + wipBranch.Name + " already")
+		return nil
+	}
+	debugInfo("registering " + identity + " as participant of " + wipBranch.Name + " in " + ref)
+	return pushMobRecord(configuration, ref, identity)
+}
+
+func collectParticipants(configuration Configuration, wipBranch Branch) []Author {
+	fetchMobRefs(configuration, participantsRefs(wipBranch))
+	return readParticipants(wipBranch)
+}
+
+// reads the participants as of the last fetch
+func readParticipants(wipBranch Branch) []Author {
+	var participants []Author
+	for _, ref := range listMobRefs(participantsRefs(wipBranch)) {
+		participant := strings.TrimSpace(readMobRecord(ref))
+		if participant != "" {
+			participants = append(participants, participant)
+		}
+	}
+	return participants
+}
+
+func removeParticipants(configuration Configuration, wipBranch Branch) {
+	deleteMobRefs(configuration, participantsRefs(wipBranch))
+}
+
+// 'mob join --register-only' registers you as co-author of the current session, or of the selected one,
+// without switching branches, e.g., for navigators who never type
+func joinSession(configuration Configuration) error {
+	if configuration.StartPickSelection != "" {
+		currentBaseBranch, _ := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+		picked, err := pickSession(configuration, currentBaseBranch, configuration.StartPickSelection)
+		if err != nil {
+			return err
+		}
+		configuration = picked
+	}
+	_, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	fetchBranches(configuration, currentWipBranch)
+
+	if !currentWipBranch.hasRemoteBranch(configuration) {
+		sayError("cannot join; there is no session on " + currentWipBranch.remote(configuration).String())
+		sayFix("To start a new session, use", configuration.mob("start"))
+		return errors.New("cannot join; there is no session")
+	}
+
+	if err := registerParticipant(configuration, currentWipBranch); err != nil {
+		return err
+	}
+	sayInfo("you joined the session on '" + currentWipBranch.String() + "' and will be added as co-author")
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestJoinSessionAddsNavigatorAsCoauthor(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	joinSession(configuration)
+	assertOnBranch(t, "master")
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	done(configuration)
+
+	output := run(t, "cat", tempDir+"/local/.git/SQUASH_MSG")
+	assertOutputContains(t, output, "\nCo-authored-by: bob <bob@example.com>\n")
+	equals(t, "", silentgit("ls-remote", "origin", participantsRefPrefix+"*"))
+	equals(t, []string{}, listMobRefs(participantsRefPrefix))
+}
+
+func TestJoinSessionWithoutSession(t *testing.T) {
+	output, configuration := setup(t)
+
+	err := joinSession(configuration)
+
+	equals(t, "cannot join; there is no session", err.Error())
+	assertOutputContains(t, output, "mob start")
+}
+
+func TestJoinRegisterOnlySelectsTheSession(t *testing.T) {
+	_, configuration := setup(t)
+	startSessionsGreenAndBlue(t, configuration)
+	setWorkingDir(tempDir + "/local")
+	configuration.JoinRegisterOnly = true
+
+	execute("join", []string{"green"}, configuration)
+
+	assertOnBranch(t, "master")
+	equals(t, []Author{"bob <bob@example.com>", "local <local@example.com>"}, collectParticipants(configuration, newBranch("mob/master-green")))
+	equals(t, []Author{"alice <alice@example.com>"}, collectParticipants(configuration, newBranch("mob/master-blue")))
+}
+
+func TestJoinSessionIsJoinRegisterOnly(t *testing.T) {
+	_, configuration := setup(t)
+	startSessionsGreenAndBlue(t, configuration)
+	setWorkingDir(tempDir + "/local")
+
+	execute("join-session", []string{"green"}, configuration)
+
+	assertOnBranch(t, "master")
+	equals(t, []Author{"bob <bob@example.com>", "local <local@example.com>"}, collectParticipants(configuration, newBranch("mob/master-green")))
+}
+
+func TestParseArgsJoinRegisterOnly(t *testing.T) {
+	configuration := getDefaultConfiguration()
+
+	command, parameters, configuration := parseArgs([]string{"mob", "join", "green", "--register-only"}, configuration)
+
+	equals(t, "join", command)
+	equals(t, []string{"green"}, parameters)
+	equals(t, true, configuration.JoinRegisterOnly)
+}
+
+func TestStartRegistersParticipant(t *testing.T) {
+	_, configuration := setup(t)
+
+	start(configuration)
+
+	equals(t, []Author{"local <local@example.com>"}, collectParticipants(configuration, newBranch("mob-session")))
+}
+
+func TestStartRegistersParticipantOnlyOnce(t *testing.T) {
+	output, configuration := setup(t)
+	start(configuration)
+	next(configuration)
+	*output = ""
+
+	start(configuration)
+
+	assertOutputNotContains(t, output, ":"+participantsRefPrefix)
+	equals(t, []Author{"local <local@example.com>"}, collectParticipants(configuration, newBranch("mob-session")))
+}
+
+func TestResetRemovesParticipants(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+
+	reset(configuration)
+
+	equals(t, "", silentgit("ls-remote", "origin", participantsRefPrefix+"*"))
+}
+
+func TestRefNameComponent(t *testing.T) {
+	equals(t, "alice-example-com", refNameComponent("Alice@example.com"))
+	equals(t, "42-bob-users-noreply-github-com", refNameComponent("42+bob@users.noreply.github.com"))
+}
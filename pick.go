@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 'mob start --pick' and 'mob join' list the active sessions of the base branch to select one of them,
+// so you don't have to retype its qualifier with --branch.
+
+type activeSession struct {
+	WipBranch     Branch
+	Qualifier     string
+	LastCommitter string
+	Age           string
+	Commits       int
+}
+
+var userInput io.Reader = os.Stdin
+
+var userInputIsTerminal = func() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func activeSessions(configuration Configuration, baseBranch Branch) []activeSession {
+	localBranches := gitBranches()
+	remoteBranches := getWipBranchesForBaseBranch(baseBranch, configuration)
+	branches := []Branch{baseBranch}
+	for _, remoteBranch := range remoteBranches {
+		branches = append(branches, newBranch(strings.TrimPrefix(remoteBranch, configuration.wipRemoteName()+"/")))
+	}
+	fetchBranches(configuration, branches...)
+
+	var sessions []activeSession
+	for _, remoteBranch := range remoteBranches {
+		name := strings.TrimPrefix(remoteBranch, configuration.wipRemoteName()+"/")
+		qualifier, _ := sessionQualifier(newBranch(name), baseBranch, configuration)
+		qualified := configuration
+		qualified.WipBranchQualifier = qualifier
+		if _, wipBranch := determineBranches(baseBranch, localBranches, qualified); !wipBranch.Is(name) {
+			debugInfo("skipping " + remoteBranch + ", as it is no wip branch of " + baseBranch.Name)
+			continue
+		}
+
+		lastCommit := strings.Split(silentgit("log", "-1", "--format=%aN%x1f%cr", remoteBranch), "\x1f")
+		commits, _ := strconv.Atoi(silentgitignorefailure("rev-list", "--count", baseBranch.remote(configuration).Name+".."+remoteBranch))
+		session := activeSession{WipBranch: newBranch(name), Qualifier: qualifier, Commits: commits}
+		if len(lastCommit) == 2 {
+			session.LastCommitter, session.Age = lastCommit[0], lastCommit[1]
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// sets the wip branch qualifier to the one of the selected session, asking for the selection if it is empty
+func pickSession(configuration Configuration, baseBranch Branch, selection string) (Configuration, error) {
+	if currentBranch := gitCurrentBranch(); currentBranch.IsWipBranch(configuration) {
+		sayInfo("you are already on wip branch '" + currentBranch.String() + "', so there is nothing to pick")
+		return configuration, nil
+	}
+
+	sessions := activeSessions(configuration, baseBranch)
+	if len(sessions) == 0 {
+		sayError("cannot join; there are no sessions on base branch '" + baseBranch.String() + "'")
+		sayFix("To start a new session, use", configuration.mob("start"))
+		return configuration, errors.New("cannot join; there are no sessions")
+	}
+	sayActiveSessions(baseBranch, sessions)
+
+	if selection == "" {
+		selection = readSelection(len(sessions))
+	}
+	if selection == "" && len(sessions) == 1 {
+		selection = "1"
+	}
+	if selection == "" {
+		sayError("cannot join; several sessions are active and none was selected")
+		sayFix("To join one of them, use", configuration.mob("join <number|name>"))
+		return configuration, errors.New("cannot join; no session selected")
+	}
+
+	found := findSessions(sessions, selection)
+	if len(found) != 1 {
+		if len(found) == 0 {
+			sayError("cannot join; '" + selection + "' matches none of the sessions")
+		} else {
+			sayError("cannot join; '" + selection + "' matches several sessions")
+		}
+		sayFix("To join one of them, use", configuration.mob("join <number|name>"))
+		return configuration, errors.New("cannot join; no unique session selected")
+	}
+
+	configuration.WipBranchQualifier = found[0].Qualifier
+	sayInfo("joining the session on '" + found[0].WipBranch.String() + "'")
+	return configuration, nil
+}
+
+func sayActiveSessions(baseBranch Branch, sessions []activeSession) {
+	width := 0
+	for _, session := range sessions {
+		if len(session.WipBranch.Name) > width {
+			width = len(session.WipBranch.Name)
+		}
+	}
+	sayInfo("active sessions on base branch '" + baseBranch.String() + "':")
+	for i, session := range sessions {
+		sayIndented(fmt.Sprintf("%d) %-*s  %s, %s, %d commits", i+1, width, session.WipBranch.Name, session.LastCommitter, session.Age, session.Commits))
+	}
+}
+
+func readSelection(count int) string {
+	return readUserInput(fmt.Sprintf("select a session [1-%d] or type part of its name: ", count))
+}
+
+// reads one line, so the answer can be piped into mob when stdin is not a terminal
+func readUserInput(prompt string) string {
+	if userInputIsTerminal() {
+		printToConsole(prompt)
+	}
+	line, err := bufio.NewReader(userInput).ReadString('\n')
+	if err != nil && line == "" {
+		debugInfo("no input read: " + err.Error())
+	}
+	return strings.TrimSpace(line)
+}
+
+// matches the number of a session, or its qualifier or wip branch exactly, by substring or by subsequence
+func findSessions(sessions []activeSession, selection string) []activeSession {
+	if number, err := strconv.Atoi(selection); err == nil {
+		if number < 1 || number > len(sessions) {
+			return nil
+		}
+		return sessions[number-1 : number]
+	}
+
+	selection = strings.ToLower(selection)
+	matchers := []func(string) bool{
+		func(name string) bool { return name == selection },
+		func(name string) bool { return strings.Contains(name, selection) },
+		func(name string) bool { return isSubsequence(selection, name) },
+	}
+	for _, matches := range matchers {
+		var found []activeSession
+		for _, session := range sessions {
+			if (session.Qualifier != "" && matches(strings.ToLower(session.Qualifier))) || matches(strings.ToLower(session.WipBranch.Name)) {
+				found = append(found, session)
+			}
+		}
+		if len(found) > 0 {
+			return found
+		}
+	}
+	return nil
+}
+
+func isSubsequence(sub string, s string) bool {
+	for _, r := range s {
+		if sub == "" {
+			break
+		}
+		if strings.HasPrefix(sub, string(r)) {
+			sub = sub[len(string(r)):]
+		}
+	}
+	return sub == ""
+}
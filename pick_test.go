@@ -0,0 +1,129 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStartPickJoinsSessionByName(t *testing.T) {
+	output, configuration := setup(t)
+	startSessionsGreenAndBlue(t, configuration)
+	setUserInput(t, "gre\n")
+
+	setWorkingDir(tempDir + "/local")
+	configuration.StartPick = true
+	err := start(configuration)
+
+	equals(t, nil, err)
+	assertOnBranch(t, "mob/master-green")
+	assertOutputContains(t, output, "active sessions on base branch 'master':")
+	assertOutputContains(t, output, "1) mob/master-blue   alice, ")
+	assertOutputContains(t, output, ", 1 commits")
+}
+
+func TestStartPickJoinsSessionByNumber(t *testing.T) {
+	_, configuration := setup(t)
+	startSessionsGreenAndBlue(t, configuration)
+
+	setWorkingDir(tempDir + "/local")
+	configuration.StartPick = true
+	configuration.StartPickSelection = "1"
+	err := start(configuration)
+
+	equals(t, nil, err)
+	assertOnBranch(t, "mob/master-blue")
+}
+
+func TestStartPickJoinsOnlySessionWithoutSelection(t *testing.T) {
+	_, configuration := setup(t)
+	setUserInput(t, "")
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	configuration.StartPick = true
+	err := start(configuration)
+
+	equals(t, nil, err)
+	assertOnBranch(t, "mob-session")
+}
+
+func TestStartPickFailsWithoutSelectionOfSeveralSessions(t *testing.T) {
+	output, configuration := setup(t)
+	startSessionsGreenAndBlue(t, configuration)
+	setUserInput(t, "")
+
+	setWorkingDir(tempDir + "/local")
+	configuration.StartPick = true
+	err := start(configuration)
+
+	equals(t, true, err != nil)
+	assertOnBranch(t, "master")
+	assertOutputContains(t, output, "cannot join; several sessions are active and none was selected")
+	assertOutputContains(t, output, "mob join <number|name>")
+}
+
+func TestStartPickFailsWithoutSessions(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.StartPick = true
+
+	err := start(configuration)
+
+	equals(t, true, err != nil)
+	assertOnBranch(t, "master")
+	assertOutputContains(t, output, "cannot join; there are no sessions on base branch 'master'")
+}
+
+func TestFindSessions(t *testing.T) {
+	sessions := []activeSession{
+		{WipBranch: newBranch("mob/main-green"), Qualifier: "green"},
+		{WipBranch: newBranch("mob/main-greenhouse"), Qualifier: "greenhouse"},
+		{WipBranch: newBranch("mob/main-blue"), Qualifier: "blue"},
+	}
+
+	equals(t, "blue", strings.Join(qualifiers(findSessions(sessions, "3")), ","))
+	equals(t, "", strings.Join(qualifiers(findSessions(sessions, "4")), ","))
+	equals(t, "green", strings.Join(qualifiers(findSessions(sessions, "Green")), ","))
+	equals(t, "green,greenhouse", strings.Join(qualifiers(findSessions(sessions, "gre")), ","))
+	equals(t, "greenhouse", strings.Join(qualifiers(findSessions(sessions, "grhs")), ","))
+	equals(t, "", strings.Join(qualifiers(findSessions(sessions, "red")), ","))
+}
+
+func TestParseArgsStartPick(t *testing.T) {
+	configuration := getDefaultConfiguration()
+
+	command, _, configuration := parseArgs([]string{"mob", "start", "--pick"}, configuration)
+
+	equals(t, "start", command)
+	equals(t, true, configuration.StartPick)
+}
+
+func startSessionsGreenAndBlue(t *testing.T, configuration Configuration) {
+	for _, session := range []struct{ dir, qualifier string }{{"alice", "blue"}, {"bob", "green"}} {
+		setWorkingDir(tempDir + "/" + session.dir)
+		qualified := configuration
+		qualified.WipBranchQualifier = session.qualifier
+		start(qualified)
+		createFile(t, session.qualifier+".txt", "contentIrrelevant")
+		next(qualified)
+	}
+}
+
+func setUserInput(t *testing.T, input string) {
+	previousInput, previousIsTerminal := userInput, userInputIsTerminal
+	userInput = strings.NewReader(input)
+	userInputIsTerminal = func() bool { return false }
+	t.Cleanup(func() {
+		userInput, userInputIsTerminal = previousInput, previousIsTerminal
+	})
+}
+
+func qualifiers(sessions []activeSession) []string {
+	var result []string
+	for _, session := range sessions {
+		result = append(result, session.Qualifier)
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+const maxWipPushAttempts = 3
+
+// pushWipBranch pushes the wip branch. If someone else pushed to it in the meantime, e.g., when two
+// people run 'mob next' at nearly the same time, the local commits are rebased onto theirs and pushed again.
+func pushWipBranch(configuration Configuration, wipBranch Branch) error {
+	remoteWipBranch := wipBranch.remote(configuration).Name
+	for attempt := 1; ; attempt++ {
+		commandString, output, err := runCommand("git", deleteEmptyStrings([]string{"push", configuration.gitHooksOption(), configuration.wipRemoteName(), wipBranch.Name})...)
+		if err == nil {
+			sayIndented(commandString)
+			return nil
+		}
+		if !isPushRejected(output) || attempt == maxWipPushAttempts {
+			sayGitError(commandString, output, err)
+			return err
+		}
+
+		sayWarning("your push was rejected, because someone else pushed to '" + remoteWipBranch + "' in the meantime")
+		git("fetch", configuration.wipRemoteName(), wipBranch.Name)
+		otherTypist := silentgitignorefailure("log", "-1", "--pretty=format:%aN", remoteWipBranch)
+		_, _, err = runCommand("git", "rebase", remoteWipBranch)
+		if err != nil {
+			unmergedFiles := silentgitignorefailure("diff", "--name-only", "--diff-filter=U")
+			silentgitignorefailure("rebase", "--abort")
+			sayError("cannot hand over; your changes conflict with the changes " + otherTypist + " pushed to '" + remoteWipBranch + "'")
+			if unmergedFiles != "" {
+				sayInfoIndented(unmergedFiles)
+			}
+			sayInfo("your changes are still in your local wip branch '" + wipBranch.Name + "'")
+			sayFix("To resolve the conflicts, use", "git rebase "+remoteWipBranch)
+			sayFix("When you resolved them and finished the rebase with 'git rebase --continue', hand over again with", configuration.mob("next"))
+			return errors.New("cannot hand over; conflicting changes on " + remoteWipBranch)
+		}
+		sayInfo("rebased your changes onto the changes " + otherTypist + " pushed to '" + remoteWipBranch + "', pushing again")
+	}
+}
+
+func isPushRejected(output string) bool {
+	return strings.Contains(output, "[rejected]") &&
+		(strings.Contains(output, "non-fast-forward") || strings.Contains(output, "fetch first"))
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNextRebasesOntoConcurrentPush(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	createFile(t, "file2.txt", "contentIrrelevant")
+	next(configuration)
+
+	assertOutputContains(t, output, "your push was rejected, because someone else pushed to 'origin/mob-session' in the meantime")
+	assertOutputContains(t, output, "rebased your changes onto the changes localother pushed to 'origin/mob-session', pushing again")
+	equals(t, silentgit("rev-parse", "mob-session"), silentgit("rev-parse", "origin/mob-session"))
+	equals(t, "2", silentgit("rev-list", "--count", "master..origin/mob-session"))
+}
+
+func TestNextStopsOnConflictingConcurrentPush(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+
+	createFile(t, "example.txt", "theirs")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	createFile(t, "example.txt", "ours")
+	assertExitCode(t, 1, func() { next(configuration) })
+
+	assertOutputContains(t, output, "cannot hand over; your changes conflict with the changes localother pushed to 'origin/mob-session'")
+	assertOutputContains(t, output, "git rebase origin/mob-session")
+	assertOnBranch(t, "mob-session")
+	equals(t, "1", silentgit("rev-list", "--count", "origin/mob-session..mob-session"))
+	assertGitStatus(t, GitStatus{})
+}
+
+func TestIsPushRejected(t *testing.T) {
+	equals(t, true, isPushRejected(" ! [rejected]        mob-session -> mob-session (fetch first)"))
+	equals(t, true, isPushRejected(" ! [rejected]        mob-session -> mob-session (non-fast-forward)"))
+	equals(t, false, isPushRejected(" ! [remote rejected] mob-session -> mob-session (pre-receive hook declined)"))
+}
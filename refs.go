@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+)
+
+// Mob keeps the bookkeeping of a session in refs below refs/mob/ instead of on the wip branch.
+// These refs are shared via the remote. Each one points to a commit with an empty tree, and the
+// commit message holds the record.
+const mobRefsPrefix = "refs/mob/"
+
+func createMobRecord(message string) string {
+	emptyTree := silentgit("mktree")
+	return silentgit("commit-tree", emptyTree, "-m", message)
+}
+
+func readMobRecord(ref string) string {
+	return silentgitignorefailure("log", "-1", "--pretty=format:%B", ref)
+}
+
+func listMobRefs(prefix string) []string {
+	output := silentgitignorefailure("for-each-ref", "--format=%(refname)", prefix)
+	if output == "" {
+		return []string{}
+	}
+	return strings.Split(output, "\n")
+}
+
+func pushMobRecord(configuration Configuration, ref string, message string) error {
+	commit := createMobRecord(message)
+	silentgit("update-ref", ref, commit)
+	return gitignorefailure(deleteEmptyStrings([]string{"push", configuration.gitHooksOption(), "--force", configuration.wipRemoteName(), commit + ":" + ref})...)
+}
+
+// prefix must end with a slash
+func fetchMobRefs(configuration Configuration, prefix string) {
+	silentgit("fetch", "--prune", configuration.wipRemoteName(), "+"+prefix+"*:"+prefix+"*")
+}
+
+// prefix must end with a slash
+func deleteMobRefs(configuration Configuration, prefix string) {
+	refs := listMobRefs(prefix)
+	if len(refs) == 0 {
+		return
+	}
+	args := []string{"push", configuration.gitHooksOption(), configuration.wipRemoteName(), "--delete"}
+	gitWithoutEmptyStrings(append(args, refs...)...)
+	for _, ref := range refs {
+		silentgit("update-ref", "-d", ref)
+	}
+}
+
+// turns any text into a single ref name component
+func refNameComponent(text string) string {
+	var result strings.Builder
+	for _, character := range strings.ToLower(text) {
+		if (character >= 'a' && character <= 'z') || (character >= '0' && character <= '9') || character == '_' || character == '-' {
+			result.WriteRune(character)
+		} else {
+			result.WriteRune('-')
+		}
+	}
+	return strings.Trim(result.String(), "-")
+}
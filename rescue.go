@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// When someone else already ended the session, the remote wip branch is gone. Work that was never
+// pushed is moved to a rescue branch instead of being deleted together with the local wip branch.
+func endSessionEndedBySomeoneElse(configuration Configuration, baseBranch Branch, wipBranch Branch, lastKnownRemoteWipCommit string) {
+	commits := localOnlyCommits(configuration, baseBranch, wipBranch, lastKnownRemoteWipCommit)
+	uncommittedChanges := hasUncommittedChanges()
+	if len(commits) == 0 && !uncommittedChanges {
+		git("checkout", baseBranch.Name)
+		backupBranch(configuration, wipBranch)
+		git("branch", "-D", wipBranch.Name)
+		sayInfo("someone else already ended your session")
+		return
+	}
+
+	sayWarning("someone else already ended your session, but you have work which was never pushed")
+	if len(commits) > 0 {
+		sayInfoIndented(strings.Join(commits, "\n"))
+	}
+	if uncommittedChanges {
+		makeWipCommit(configuration)
+	}
+
+	from := lastKnownRemoteWipCommit
+	if from == "" {
+		from = silentgit("merge-base", wipBranch.Name, baseBranch.remote(configuration).Name)
+	}
+	from = silentgit("rev-parse", "--short", from)
+	rescueBranch := rescueBranchName(wipBranch, time.Now())
+	git("branch", "--move", wipBranch.Name, rescueBranch)
+	git("checkout", baseBranch.Name)
+
+	sayInfo("moved " + describeRescuedWork(len(commits), uncommittedChanges) + " to branch '" + rescueBranch + "'")
+	sayFix("To apply them to '"+baseBranch.Name+"' as uncommitted changes, use", "git cherry-pick --no-commit "+from+".."+rescueBranch)
+	sayFix("To delete the rescue branch afterwards, use", "git branch -D "+rescueBranch)
+}
+
+// commits on the local wip branch which were neither pushed nor merged into the base branch
+func localOnlyCommits(configuration Configuration, baseBranch Branch, wipBranch Branch, lastKnownRemoteWipCommit string) []string {
+	args := []string{"log", "--pretty=format:%h %s", wipBranch.Name, "--not", baseBranch.remote(configuration).Name}
+	if lastKnownRemoteWipCommit != "" {
+		args = append(args, lastKnownRemoteWipCommit)
+	}
+	return removeEmptyValues(strings.Split(silentgitignorefailure(args...), "\n"))
+}
+
+func rescueBranchName(wipBranch Branch, now time.Time) string {
+	return "mob-rescue/" + wipBranch.Name + "/" + now.Format("20060102-150405")
+}
+
+func describeRescuedWork(commits int, uncommittedChanges bool) string {
+	var parts []string
+	if commits > 0 {
+		parts = append(parts, strconv.Itoa(commits)+" local commit(s)")
+	}
+	if uncommittedChanges {
+		parts = append(parts, "your uncommitted changes")
+	}
+	return strings.Join(parts, " and ")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDoneRescuesUnpushedWorkWhenSessionEndedBySomeoneElse(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	start(configuration)
+	pushedCommit := silentgit("rev-parse", "--short", "HEAD")
+
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+	done(configuration)
+	git("commit", "-m", "finished")
+	git("push")
+
+	setWorkingDir(tempDir + "/local")
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "unpushed commit")
+	createFile(t, "file3.txt", "contentIrrelevant")
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertNoLocalBranch(t, "mob-session")
+	assertOutputContains(t, output, "someone else already ended your session, but you have work which was never pushed")
+	assertOutputContains(t, output, "moved 1 local commit(s) and your uncommitted changes to branch 'mob-rescue/mob-session/")
+	assertOutputContains(t, output, "git cherry-pick --no-commit "+pushedCommit+"..mob-rescue/mob-session/")
+
+	rescueBranch := silentgit("for-each-ref", "--format=%(refname:short)", "refs/heads/mob-rescue/")
+	git("merge", "origin/master", "--ff-only")
+	git("cherry-pick", "--no-commit", pushedCommit+".."+rescueBranch)
+	assertGitStatus(t, GitStatus{
+		"file2.txt": "A",
+		"file3.txt": "A",
+	})
+}
+
+func TestDoneDeletesWipBranchWithoutUnpushedWorkWhenSessionEndedBySomeoneElse(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	start(configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+	done(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertNoLocalBranch(t, "mob-session")
+	assertOutputContains(t, output, "someone else already ended your session")
+	assertOutputNotContains(t, output, "mob-rescue")
+}
+
+func TestRescueBranchName(t *testing.T) {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	equals(t, "mob-rescue/mob/main/20210102-030405", rescueBranchName(newBranch("mob/main"), now))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// The metadata of a session, like its base branch and the ref it was started from, is recorded in the remote ref
+// refs/mob/meta/<wip-branch> when the session starts. So every clone determines the same base branch, even if the
+// base branch isn't checked out locally or its name contains the separator of the qualifier.
+const metaRefPrefix = mobRefsPrefix + "meta/"
+
+type sessionMeta struct {
+	BaseBranch string
+	From       string
+	Creator    Author
+	CreatedAt  time.Time
+	Qualifier  string
+	TimerRoom  string
+}
+
+func metaRef(wipBranch Branch) string {
+	return metaRefPrefix + wipBranch.Name
+}
+
+func (meta sessionMeta) record() string {
+	return "base=" + meta.BaseBranch + "\n" +
+		"from=" + meta.From + "\n" +
+		"creator=" + meta.Creator + "\n" +
+		"created=" + meta.CreatedAt.UTC().Format(time.RFC3339) + "\n" +
+		"qualifier=" + meta.Qualifier + "\n" +
+		"room=" + meta.TimerRoom + "\n"
+}
+
+func parseSessionMeta(record string) sessionMeta {
+	var meta sessionMeta
+	for _, line := range strings.Split(record, "\n") {
+		keyValue := strings.SplitN(line, "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		key, value := keyValue[0], keyValue[1]
+		switch key {
+		case "base":
+			meta.BaseBranch = value
+		case "from":
+			meta.From = value
+		case "creator":
+			meta.Creator = value
+		case "created":
+			meta.CreatedAt, _ = time.Parse(time.RFC3339, value)
+		case "qualifier":
+			meta.Qualifier = value
+		case "room":
+			meta.TimerRoom = value
+		}
+	}
+	return meta
+}
+
+func recordSessionStart(configuration Configuration, wipBranch Branch, baseBranch Branch, from string) error {
+	meta := sessionMeta{
+		BaseBranch: baseBranch.Name,
+		From:       from,
+		Creator:    gitUserIdentity(),
+		CreatedAt:  time.Now(),
+		Qualifier:  configuration.WipBranchQualifier,
+		TimerRoom:  configuration.TimerRoom,
+	}
+	if configuration.TimerRoomUseWipBranchQualifier && configuration.WipBranchQualifier != "" {
+		meta.TimerRoom = configuration.WipBranchQualifier
+	}
+	return pushMobRecord(configuration, metaRef(wipBranch), meta.record())
+}
+
+// reads the metadata as of the last fetch
+func readSessionMeta(wipBranch Branch) (sessionMeta, bool) {
+	if silentgitignorefailure("rev-parse", "--verify", "--quiet", metaRef(wipBranch)) == "" {
+		return sessionMeta{}, false
+	}
+	return parseSessionMeta(readMobRecord(metaRef(wipBranch))), true
+}
+
+func removeSessionMeta(configuration Configuration, wipBranch Branch) {
+	if _, recorded := readSessionMeta(wipBranch); !recorded {
+		return
+	}
+	commandString, output, err := runCommand("git", deleteEmptyStrings([]string{"push", configuration.gitHooksOption(), configuration.wipRemoteName(), "--delete", metaRef(wipBranch)})...)
+	if err != nil {
+		debugInfo(output)
+	} else {
+		sayIndented(commandString)
+	}
+	silentgit("update-ref", "-d", metaRef(wipBranch))
+}
+
+// resolves the ref to start from, or returns an empty string if it is not a commit
+func resolveStartRef(ref string) string {
+	return silentgitignorefailure("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+}
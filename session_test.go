@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartFromTag(t *testing.T) {
+	output, configuration := setup(t)
+	git("tag", "v1.0")
+	createFileAndCommitIt(t, "later.txt", "contentIrrelevant", "later commit")
+	git("push")
+	configuration.StartFrom = "v1.0"
+
+	start(configuration)
+
+	assertOnBranch(t, "mob-session")
+	equals(t, silentgit("rev-parse", "v1.0"), silentgit("rev-parse", "HEAD"))
+	equals(t, silentgit("rev-parse", "v1.0"), silentgit("rev-parse", "origin/mob-session"))
+	meta, recorded := readSessionMeta(newBranch("mob-session"))
+	equals(t, true, recorded)
+	equals(t, "master", meta.BaseBranch)
+	equals(t, "v1.0", meta.From)
+
+	status(configuration)
+	assertOutputContains(t, output, "the session was started from 'v1.0'; 'mob done' merges it into 'master'")
+}
+
+func TestStartFromLocalCommits(t *testing.T) {
+	_, configuration := setup(t)
+	createFileAndCommitIt(t, "local.txt", "contentIrrelevant", "unpushed commit")
+	configuration.StartFrom = "master"
+
+	err := start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	done(configuration)
+
+	equals(t, nil, err)
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{
+		"file1.txt": "A",
+	})
+	equals(t, "1", silentgit("rev-list", "--count", "origin/master..master"))
+}
+
+func TestStartFromUnknownRef(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.StartFrom = "does-not-exist"
+
+	err := start(configuration)
+
+	equals(t, true, err != nil)
+	assertOnBranch(t, "master")
+	assertOutputContains(t, output, "cannot start; 'does-not-exist' is not a commit, tag or branch")
+}
+
+func TestParseArgsStartFrom(t *testing.T) {
+	configuration := getDefaultConfiguration()
+
+	command, parameters, configuration := parseArgs([]string{"mob", "start", "--from", "v1.0"}, configuration)
+
+	equals(t, "start", command)
+	equals(t, "", strings.Join(parameters, ""))
+	equals(t, "v1.0", configuration.StartFrom)
+}
+
+func TestSessionMetaIsSharedWithTheMob(t *testing.T) {
+	_, configuration := setup(t)
+	setWorkingDir(tempDir + "/localother")
+	git("checkout", "-b", "feature-x")
+	git("push", "--set-upstream", "origin", "feature-x")
+	configuration.TimerRoom = "room-x"
+	start(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	configuration.TimerRoom = ""
+	fetchBranches(configuration, newBranch("mob/feature-x"))
+	git("checkout", "-b", "mob/feature-x", "origin/mob/feature-x")
+
+	baseBranch, wipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	equals(t, "feature-x", baseBranch.Name)
+	equals(t, "mob/feature-x", wipBranch.Name)
+	equals(t, "room-x", getMobTimerRoom(configuration))
+	meta, _ := readSessionMeta(wipBranch)
+	equals(t, "localother <localother@example.com>", meta.Creator)
+}
+
+func TestDoneRemovesSessionMeta(t *testing.T) {
+	_, configuration := setup(t)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+
+	done(configuration)
+
+	equals(t, "", silentgit("ls-remote", configuration.RemoteName, metaRefPrefix+"*"))
+	_, recorded := readSessionMeta(newBranch("mob-session"))
+	equals(t, false, recorded)
+}
+
+func TestParseSessionMeta(t *testing.T) {
+	createdAt := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	meta := sessionMeta{BaseBranch: "feature-x", From: "v1.0", Creator: "alice <alice@example.com>", CreatedAt: createdAt, Qualifier: "green", TimerRoom: "room=1"}
+
+	equals(t, meta, parseSessionMeta(meta.record()))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+)
+
+// squashPerTurn folds the consecutive wip commits of every typist into a single commit authored by them,
+// so 'git blame' on the base branch reflects the rotation. Manual commits are kept as they are.
+func squashPerTurn(configuration Configuration, coauthors []Author) {
+	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	if hasUncommittedChanges() {
+		makeWipCommit(configuration)
+	}
+	mergeBase := silentgit("merge-base", currentWipBranch.String(), currentBaseBranch.String())
+
+	directory := loadCoauthorDirectory()
+	mob := append(coauthors, directory.trailerIdentity(gitUserIdentity()))
+	turns := squashTurns(wipBranchCommits(mergeBase, currentWipBranch.String()), configuration, directory)
+	for i, turn := range turns {
+		if configuration.isWipCommitMessage(turn.Representative.Message) {
+			turns[i].Representative = createTurnCommit(turn, mob, directory)
+		}
+	}
+
+	backupBranch(configuration, currentWipBranch)
+	sayInfo("rewriting history of '" + currentWipBranch.String() + "': squashing the wip commits of every turn into one commit of its typist.")
+	git("reset", "--soft", createSquashedCommits(turns, mergeBase))
+	sayInfo("resulting history is:")
+	sayLastCommitsWithMessage(currentBaseBranch.String(), currentWipBranch.String())
+
+	gitWithoutEmptyStrings("push", "--force-with-lease", configuration.gitHooksOption(), configuration.wipRemoteName(), currentWipBranch.Name)
+}
+
+// a turn is a run of wip commits by the same typist; manual commits are turns of their own
+func squashTurns(commits []wipBranchCommit, configuration Configuration, directory coauthorDirectory) []squashedCommit {
+	var turns []squashedCommit
+	for _, commit := range commits {
+		if len(turns) > 0 && configuration.isWipCommitMessage(commit.Message) {
+			previous := &turns[len(turns)-1]
+			if configuration.isWipCommitMessage(previous.Representative.Message) &&
+				directory.resolve(previous.Representative.author()) == directory.resolve(commit.author()) {
+				previous.Commits = append(previous.Commits, commit)
+				previous.Representative = commit
+				continue
+			}
+		}
+		turns = append(turns, squashedCommit{Commits: []wipBranchCommit{commit}, Representative: commit})
+	}
+	return turns
+}
+
+// the turn commit is authored by the typist and credits the rest of the mob
+func createTurnCommit(turn squashedCommit, mob []Author, directory coauthorDirectory) wipBranchCommit {
+	first := turn.Commits[0]
+	last := turn.Commits[len(turn.Commits)-1]
+
+	typist := parseCoauthorIdentity(directory.resolve(last.author()))
+	if typist.Email == "" {
+		typist.Email = last.AuthorEmail
+	}
+	var coauthors []Author
+	for _, coauthor := range removeDuplicateValues(mob) {
+		if coauthor != directory.trailerIdentity(last.author()) {
+			coauthors = append(coauthors, coauthor)
+		}
+	}
+	files := removeEmptyValues(strings.Split(silentgit("diff", "--name-only", first.Parent, last.Hash), "\n"))
+
+	commit := last
+	commit.AuthorName = typist.Name
+	commit.AuthorEmail = typist.Email
+	commit.Message = createTurnCommitMessage(typist.Name, files, coauthors)
+	return commit
+}
+
+func createTurnCommitMessage(typist string, files []string, coauthors []Author) string {
+	message := "mob turn of " + typist + "\n"
+	if len(files) > 0 {
+		message += "\nfiles touched:\n"
+		for _, file := range files {
+			message += "- " + file + "\n"
+		}
+	}
+	if len(coauthors) > 0 {
+		message += "\n" + strings.Join(createCoauthorTrailers(coauthors), "\n") + "\n"
+	}
+	return message
+}
+
+func (commit wipBranchCommit) author() Author {
+	return commit.AuthorName + " <" + commit.AuthorEmail + ">"
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDoneSquashPerTurn(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = SquashPerTurn
+
+	setWorkingDir(tempDir + "/alice")
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	start(configuration)
+	createFile(t, "file3.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(configuration)
+	createFile(t, "file4.txt", "contentIrrelevant")
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	equals(t, []string{
+		"local <local@example.com>",
+		"bob <bob@example.com>",
+		"alice <alice@example.com>",
+	}, strings.Split(silentgit("log", "origin/master..HEAD", "--pretty=format:%an <%ae>"), "\n"))
+	equals(t, "mob turn of alice\n\n"+
+		"files touched:\n- file1.txt\n- file2.txt\n\n"+
+		"Co-authored-by: bob <bob@example.com>\nCo-authored-by: local <local@example.com>",
+		silentgit("log", "-1", "--pretty=format:%B", "HEAD~2"))
+	equals(t, "mob turn of local\n\n"+
+		"files touched:\n- file4.txt\n\n"+
+		"Co-authored-by: bob <bob@example.com>\nCo-authored-by: alice <alice@example.com>",
+		silentgit("log", "-1", "--pretty=format:%B", "HEAD"))
+}
+
+func TestSquashTurns(t *testing.T) {
+	configuration := getDefaultConfiguration()
+	commits := []wipBranchCommit{
+		turnCommit("w1", "alice", configuration.WipCommitMessage),
+		turnCommit("w2", "alice", configuration.WipCommitMessage),
+		turnCommit("c1", "alice", "manual commit"),
+		turnCommit("w3", "alice", configuration.WipCommitMessage),
+		turnCommit("w4", "bob", configuration.WipCommitMessage),
+	}
+
+	result := squashTurns(commits, configuration, coauthorDirectory{})
+
+	equals(t, []squashedCommit{
+		{Commits: commits[0:2], Representative: commits[1]},
+		{Commits: commits[2:3], Representative: commits[2]},
+		{Commits: commits[3:4], Representative: commits[3]},
+		{Commits: commits[4:5], Representative: commits[4]},
+	}, result)
+}
+
+func TestSquashTurnsUsesAliases(t *testing.T) {
+	configuration := getDefaultConfiguration()
+	directory := parseCoauthorDirectory(coauthorDirectory{}, strings.NewReader("alias Alice <alice@example.com> = <alice@work.example>"))
+	commits := []wipBranchCommit{
+		turnCommit("w1", "alice", configuration.WipCommitMessage),
+		{Hash: "w2", AuthorName: "alice", AuthorEmail: "alice@work.example", Message: configuration.WipCommitMessage},
+	}
+
+	result := squashTurns(commits, configuration, directory)
+
+	equals(t, []squashedCommit{{Commits: commits, Representative: commits[1]}}, result)
+}
+
+func TestCreateTurnCommitMessage(t *testing.T) {
+	message := createTurnCommitMessage("alice", []string{"file1.txt"}, []Author{"bob <bob@example.com>"})
+
+	equals(t, "mob turn of alice\n\nfiles touched:\n- file1.txt\n\nCo-authored-by: bob <bob@example.com>\n", message)
+}
+
+func turnCommit(hash string, author string, message string) wipBranchCommit {
+	return wipBranchCommit{Hash: hash, AuthorName: author, AuthorEmail: author + "@example.com", Message: message + "\n"}
+}
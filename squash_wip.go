@@ -1,27 +1,36 @@
 package main
 
 import (
-	"io"
-	"io/ioutil"
-	"os"
 	"strconv"
 	"strings"
 )
 
-type Replacer func(string) string
+// wipBranchCommit is a commit on the wip branch, as needed to recreate it with 'git commit-tree'
+type wipBranchCommit struct {
+	Hash        string
+	Parent      string
+	Tree        string
+	AuthorName  string
+	AuthorEmail string
+	AuthorDate  string
+	Message     string
+}
+
+// squashedCommit replaces a run of wip commits and the manual commit following them.
+// It gets the tree of its last commit and author, date and message of its representative.
+type squashedCommit struct {
+	Commits        []wipBranchCommit
+	Representative wipBranchCommit
+}
 
 func squashWip(configuration Configuration) {
 	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
 	mergeBase := silentgit("merge-base", currentWipBranch.String(), currentBaseBranch.String())
 
-	originalGitEditor, originalGitSequenceEditor := getEnvGitEditor()
-	setEnvGitEditor(
-		mobExecutable()+" squash-wip --git-editor",
-		mobExecutable()+" squash-wip --git-sequence-editor",
-	)
+	backupBranch(configuration, currentWipBranch)
 	sayInfo("rewriting history of '" + currentWipBranch.String() + "': squashing wip commits while keeping manual commits.")
-	git("rebase", "--interactive", "--keep-empty", mergeBase)
-	setEnvGitEditor(originalGitEditor, originalGitSequenceEditor)
+	squashedCommits := squashWipCommits(wipBranchCommits(mergeBase, currentWipBranch.String()), configuration)
+	git("reset", "--soft", createSquashedCommits(squashedCommits, mergeBase))
 	sayInfo("resulting history is:")
 	sayLastCommitsWithMessage(currentBaseBranch.String(), currentWipBranch.String())
 	if lastCommitIsWipCommit(configuration) { // last commit is wip commit
@@ -29,173 +38,102 @@ func squashWip(configuration Configuration) {
 		git("reset", "--soft", "HEAD^")
 	}
 
-	git("push", "--force", configuration.gitHooksOption())
-}
-
-func lastCommitIsWipCommit(configuration Configuration) bool {
-	return strings.HasPrefix(lastCommitMessage(), configuration.WipCommitMessage)
-}
-
-func lastCommitMessage() string {
-	return silentgit("log", "-1", "--pretty=format:%B")
-}
-
-func sayLastCommitsWithMessage(currentBaseBranch string, currentWipBranch string) {
-	commitsBaseWipBranch := currentBaseBranch + ".." + currentWipBranch
-	log := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=oneline", "--abbrev-commit")
-	lines := strings.Split(log, "\n")
-	if len(lines) > 10 {
-		sayInfo("wip branch '" + currentWipBranch + "' contains " + strconv.Itoa(len(lines)) + " commits. The last 10 were:")
-		lines = lines[:10]
-	}
-	output := strings.Join(lines, "\n")
-	say(output)
-}
-
-func setEnvGitEditor(gitEditor string, gitSequenceEditor string) {
-	os.Setenv("GIT_EDITOR", gitEditor)
-	os.Setenv("GIT_SEQUENCE_EDITOR", gitSequenceEditor)
-}
-
-func getEnvGitEditor() (gitEditor string, gitSequenceEditor string) {
-	gitEditor = os.Getenv("GIT_EDITOR")
-	gitSequenceEditor = os.Getenv("GIT_SEQUENCE_EDITOR")
-	return
+	gitWithoutEmptyStrings("push", "--force-with-lease", configuration.gitHooksOption(), configuration.wipRemoteName(), currentWipBranch.Name)
 }
 
-func mobExecutable() string {
-	if isTestEnvironment() {
-		wd, _ := os.Getwd()
-		return "go run $(ls -1 " + wd + "/*.go | grep -v _test.go)"
-	} else {
-		return "mob"
-	}
-}
-
-func isTestEnvironment() bool {
-	return strings.HasSuffix(os.Args[0], ".test") ||
-		strings.HasSuffix(os.Args[0], "_test") ||
-		os.Args[1] == "-test.v"
-}
-
-// used for non-interactive fixing of commit messages of squashed commits
-func squashWipGitEditor(fileName string, configuration Configuration) {
-	replaceFileContents(fileName, func(input string) string {
-		return commentWipCommits(input, configuration)
-	})
-}
-
-// used for non-interactive rebase to squash post-wip-commits
-func squashWipGitSequenceEditor(fileName string, configuration Configuration) {
-	replaceFileContents(fileName, func(input string) string {
-		return markPostWipCommitsForSquashing(input, configuration)
-	})
-}
-
-func replaceFileContents(fileName string, replacer Replacer) {
-	file, _ := os.OpenFile(fileName, os.O_RDWR, 0666)
-	input, err := ioutil.ReadAll(file)
+// lists the commits from oldest to newest, following the first parent of merge commits
+func wipBranchCommits(mergeBase string, wipBranch string) []wipBranchCommit {
+	commandString, output, err := runCommand("git", "log", "--reverse", "--first-parent", "--date=raw",
+		"--pretty=format:%H%x1f%P%x1f%T%x1f%an%x1f%ae%x1f%ad%x1f%B%x1e", mergeBase+".."+wipBranch)
 	if err != nil {
-		panic(err)
+		sayGitError(commandString, output, err)
+		exit(1)
 	}
 
-	result := replacer(string(input))
-
-	file.Seek(0, io.SeekStart)
-	file.Truncate(0)
-	file.WriteString(result)
-	file.Close()
-}
-
-func commentWipCommits(input string, configuration Configuration) string {
-	var result []string
-	ignoreBlock := false
-	lines := strings.Split(input, "\n")
-	for idx, line := range lines {
-		if configuration.isWipCommitMessage(line) {
-			ignoreBlock = true
-		} else if line == "" && isNextLineComment(lines, idx) {
-			ignoreBlock = false
+	var commits []wipBranchCommit
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(strings.TrimPrefix(record, "\n"), "\x1f", 7)
+		if len(fields) < 7 {
+			continue
 		}
-
-		if ignoreBlock {
-			result = append(result, "# "+line)
-		} else {
-			result = append(result, line)
+		parent := "" // a root commit has none
+		if parents := strings.Fields(fields[1]); len(parents) > 0 {
+			parent = parents[0]
 		}
+		commits = append(commits, wipBranchCommit{
+			Hash:        fields[0],
+			Parent:      parent,
+			Tree:        fields[2],
+			AuthorName:  fields[3],
+			AuthorEmail: fields[4],
+			AuthorDate:  fields[5],
+			Message:     fields[6],
+		})
 	}
-	return strings.Join(result, "\n")
-}
-
-func isNextLineComment(lines []string, currentLineIndex int) bool {
-	return len(lines) > currentLineIndex+1 && strings.HasPrefix(lines[currentLineIndex+1], "#")
-}
-
-func markPostWipCommitsForSquashing(input string, configuration Configuration) string {
-	var result []string
-
-	inputLines := strings.Split(input, "\n")
-	for index := range inputLines {
-		markedLine := markLine(inputLines, index, configuration)
-		result = append(result, markedLine)
-	}
-
-	return strings.Join(result, "\n")
-}
-
-func markLine(inputLines []string, i int, configuration Configuration) string {
-	var resultLine = inputLines[i]
-	previousLine := previousLine(inputLines, i)
-	if isWipCommitLine(previousLine, configuration) {
-		forthComingLines := inputLines[i:]
-
-		if hasOnlyWipCommits(forthComingLines, configuration) {
-			resultLine = markFixup(inputLines[i])
-		} else {
-			resultLine = markSquash(inputLines[i])
+	return commits
+}
+
+// wip commits are squashed into the following manual commit, final wip commits into the first of them
+func squashWipCommits(commits []wipBranchCommit, configuration Configuration) []squashedCommit {
+	var squashedCommits []squashedCommit
+	var pending []wipBranchCommit
+	for _, commit := range commits {
+		pending = append(pending, commit)
+		if !configuration.isWipCommitMessage(commit.Message) {
+			squashedCommits = append(squashedCommits, squashedCommit{Commits: pending, Representative: commit})
+			pending = nil
 		}
 	}
-	return resultLine
-}
-
-func previousLine(inputLines []string, currentIndex int) string {
-	var previousLine = ""
-	if currentIndex > 0 {
-		previousLine = inputLines[currentIndex-1]
+	if len(pending) > 0 {
+		squashedCommits = append(squashedCommits, squashedCommit{Commits: pending, Representative: pending[0]})
 	}
-	return previousLine
+	return squashedCommits
 }
 
-func hasOnlyWipCommits(forthComingLines []string, configuration Configuration) bool {
-	var onlyWipCommits = true
-	for _, forthComingLine := range forthComingLines {
-		if isPick(forthComingLine) && isManualCommit(forthComingLine, configuration) {
-			onlyWipCommits = false
+// returns the hash of the last created commit; unchanged commits are reused as they are
+func createSquashedCommits(squashedCommits []squashedCommit, parent string) string {
+	for _, squashed := range squashedCommits {
+		last := squashed.Commits[len(squashed.Commits)-1]
+		if len(squashed.Commits) == 1 && last.Parent == parent && squashed.Representative == last {
+			parent = last.Hash
+			continue
 		}
-	}
-	return onlyWipCommits
-}
-
-func markSquash(line string) string {
-	return strings.Replace(line, "pick ", "squash ", 1)
-}
 
-func markFixup(line string) string {
-	return strings.Replace(line, "pick ", "fixup ", 1)
-}
-
-func isWipCommitLine(line string, configuration Configuration) bool {
-	return isPick(line) && isWipCommit(line, configuration)
+		representative := squashed.Representative
+		args := []string{"commit-tree", last.Tree, "-m", representative.Message}
+		if parent != "" {
+			args = append(args, "-p", parent)
+		}
+		commandString, output, err := runCommandWithEnvironment([]string{
+			"GIT_AUTHOR_NAME=" + representative.AuthorName,
+			"GIT_AUTHOR_EMAIL=" + representative.AuthorEmail,
+			"GIT_AUTHOR_DATE=" + representative.AuthorDate,
+		}, "git", args...)
+		if err != nil {
+			sayGitError(commandString, output, err)
+			exit(1)
+		}
+		parent = strings.TrimSpace(output)
+	}
+	return parent
 }
 
-func isManualCommit(line string, configuration Configuration) bool {
-	return !isWipCommit(line, configuration)
+func lastCommitIsWipCommit(configuration Configuration) bool {
+	return strings.HasPrefix(lastCommitMessage(), configuration.WipCommitMessage)
 }
 
-func isWipCommit(line string, configuration Configuration) bool {
-	return strings.Contains(line, configuration.WipCommitMessage)
+func lastCommitMessage() string {
+	return silentgit("log", "-1", "--pretty=format:%B")
 }
 
-func isPick(line string) bool {
-	return strings.HasPrefix(line, "pick ")
+func sayLastCommitsWithMessage(currentBaseBranch string, currentWipBranch string) {
+	commitsBaseWipBranch := currentBaseBranch + ".." + currentWipBranch
+	log := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=oneline", "--abbrev-commit")
+	lines := strings.Split(log, "\n")
+	if len(lines) > 10 {
+		sayInfo("wip branch '" + currentWipBranch + "' contains " + strconv.Itoa(len(lines)) + " commits. The last 10 were:")
+		lines = lines[:10]
+	}
+	output := strings.Join(lines, "\n")
+	say(output)
 }
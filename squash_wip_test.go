@@ -1,8 +1,6 @@
 package main
 
 import (
-	"fmt"
-	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
@@ -138,205 +136,109 @@ func TestCommitsOnCurrentBranch(t *testing.T) {
 	}, commits)
 }
 
-func TestMarkSquashWip_singleManualCommit(t *testing.T) {
-	configuration := getDefaultConfiguration()
-	input := `pick c51a56d new file
-
-# Rebase ...`
+func TestSquashWipCommits_keepsAuthorsAndDates(t *testing.T) {
+	_, configuration := setup(t)
+	wipCommit(t, configuration, "file1.txt")
+	start(configuration)
+	createFile(t, "file2.txt", "contentIrrelevant")
+	silentgit("add", "--all")
+	silentgit("commit", "--author", "Alice <alice@example.com>", "--date", "2021-01-02T03:04:05+01:00", "-m", "manual commit\n\nwith body")
 
-	result := markPostWipCommitsForSquashing(input, configuration)
+	squashWip(configuration)
 
-	equals(t, input, result)
+	equals(t, "Alice <alice@example.com> 2021-01-02T03:04:05+01:00", silentgit("log", "-1", "--pretty=format:%an <%ae> %aI"))
+	equals(t, "manual commit\n\nwith body", silentgit("log", "-1", "--pretty=format:%B"))
+	equals(t, []string{"file1.txt", "file2.txt"}, strings.Split(silentgit("show", "--pretty=format:", "--name-only", "HEAD"), "\n"))
 }
 
-func TestMarkSquashWip_manyManualCommits(t *testing.T) {
-	configuration := getDefaultConfiguration()
-	input := `pick c51a56d new file
-pick 63ef7a4 another commit
-
-# Rebase ...`
+func TestSquashWipCommits_keepsUnchangedCommits(t *testing.T) {
+	_, configuration := setup(t)
+	manualCommit(t, configuration, "file1.txt", "first manual commit")
+	start(configuration)
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "second manual commit")
+	head := silentgit("rev-parse", "HEAD")
 
-	result := markPostWipCommitsForSquashing(input, configuration)
+	squashWip(configuration)
 
-	equals(t, input, result)
+	equals(t, head, silentgit("rev-parse", "HEAD"))
 }
 
-func TestMarkSquashWip_wipCommitFollowedByManualCommit(t *testing.T) {
-	configuration := getDefaultConfiguration()
-	input := fmt.Sprintf(`pick 01a9a31 %s
-pick c51a56d manual commit
-
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick 01a9a31 %s
-squash c51a56d manual commit
-
-# Rebase ...`, configuration.WipCommitMessage)
-
-	result := markPostWipCommitsForSquashing(input, configuration)
-
-	equals(t, expected, result)
+func TestWipBranchCommits_withRootCommit(t *testing.T) {
+	_, configuration := setup(t)
+	git("checkout", "--orphan", "unrelated")
+	createFileAndCommitIt(t, "file1.txt", "contentIrrelevant", configuration.WipCommitMessage)
+	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "manual commit")
+
+	commits := wipBranchCommits("master", "unrelated")
+
+	equals(t, 2, len(commits))
+	equals(t, "", commits[0].Parent)
+	equals(t, commits[0].Hash, commits[1].Parent)
+	squashed := createSquashedCommits(squashWipCommits(commits, configuration), "")
+	equals(t, squashed, silentgit("rev-list", "--max-parents=0", squashed))
+	equals(t, "manual commit", silentgit("log", "-1", "--pretty=format:%s", squashed))
 }
 
-func TestMarkSquashWip_manyWipCommitsFollowedByManualCommit(t *testing.T) {
+func TestSquashWipCommits_singleManualCommit(t *testing.T) {
 	configuration := getDefaultConfiguration()
-	input := fmt.Sprintf(`pick 01a9a31 %[1]s
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-pick c51a56d manual commit
-
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick 01a9a31 %[1]s
-squash 01a9a32 %[1]s
-squash 01a9a33 %[1]s
-squash c51a56d manual commit
+	commits := []wipBranchCommit{manual("c1")}
 
-# Rebase ...`, configuration.WipCommitMessage)
+	result := squashWipCommits(commits, configuration)
 
-	result := markPostWipCommitsForSquashing(input, configuration)
-
-	equals(t, expected, result)
+	equals(t, []squashedCommit{{Commits: commits, Representative: commits[0]}}, result)
 }
 
-func TestMarkSquashWip_manualCommitFollowedByWipCommit(t *testing.T) {
+func TestSquashWipCommits_manyManualCommits(t *testing.T) {
 	configuration := getDefaultConfiguration()
-	input := fmt.Sprintf(`pick c51a56d manual commit
-pick 01a9a31 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick c51a56d manual commit
-pick 01a9a31 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
+	commits := []wipBranchCommit{manual("c1"), manual("c2")}
 
-	result := markPostWipCommitsForSquashing(input, configuration)
+	result := squashWipCommits(commits, configuration)
 
-	equals(t, expected, result)
+	equals(t, []squashedCommit{
+		{Commits: commits[0:1], Representative: commits[0]},
+		{Commits: commits[1:2], Representative: commits[1]},
+	}, result)
 }
 
-func TestMarkSquashWip_manualCommitFollowedByManyWipCommits(t *testing.T) {
+func TestSquashWipCommits_manyWipCommitsFollowedByManualCommit(t *testing.T) {
 	configuration := getDefaultConfiguration()
-	input := fmt.Sprintf(`pick c51a56d manual commit
-pick 01a9a31 %[1]s
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick c51a56d manual commit
-pick 01a9a31 %[1]s
-fixup 01a9a32 %[1]s
-fixup 01a9a33 %[1]s
-
-# Rebase ...`, configuration.WipCommitMessage)
+	commits := []wipBranchCommit{wip("w1", configuration), wip("w2", configuration), manual("c1")}
 
-	result := markPostWipCommitsForSquashing(input, configuration)
+	result := squashWipCommits(commits, configuration)
 
-	equals(t, expected, result)
+	equals(t, []squashedCommit{{Commits: commits, Representative: commits[2]}}, result)
 }
 
-func TestMarkSquashWip_wipThenManualCommitFollowedByManyWipCommits(t *testing.T) {
+func TestSquashWipCommits_manualCommitFollowedByManyWipCommits(t *testing.T) {
 	configuration := getDefaultConfiguration()
-	input := fmt.Sprintf(`pick 01a9a31 %[1]s
-pick c51a56d manual commit
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
+	commits := []wipBranchCommit{manual("c1"), wip("w1", configuration), wip("w2", configuration)}
 
-# Rebase ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`pick 01a9a31 %[1]s
-squash c51a56d manual commit
-pick 01a9a32 %[1]s
-fixup 01a9a33 %[1]s
+	result := squashWipCommits(commits, configuration)
 
-# Rebase ...`, configuration.WipCommitMessage)
-
-	result := markPostWipCommitsForSquashing(input, configuration)
-
-	equals(t, expected, result)
+	equals(t, []squashedCommit{
+		{Commits: commits[0:1], Representative: commits[0]},
+		{Commits: commits[1:3], Representative: commits[1]},
+	}, result)
 }
 
-func TestCommentWipCommits_oneWipAndOneManualCommit(t *testing.T) {
+func TestSquashWipCommits_wipThenManualCommitFollowedByManyWipCommits(t *testing.T) {
 	configuration := getDefaultConfiguration()
-	input := fmt.Sprintf(`# This is a combination of 2 commits.
-# This is the 1st commit message:
-
-%s
-
-# This is the commit message #2:
-
-manual commit
-
-# Please enter ...`, configuration.WipCommitMessage)
-	expected := fmt.Sprintf(`# This is a combination of 2 commits.
-# This is the 1st commit message:
+	commits := []wipBranchCommit{wip("w1", configuration), manual("c1"), wip("w2", configuration), wip("w3", configuration)}
 
-# %s
+	result := squashWipCommits(commits, configuration)
 
-# This is the commit message #2:
-
-manual commit
-
-# Please enter ...`, configuration.WipCommitMessage)
-
-	result := commentWipCommits(input, configuration)
-
-	equals(t, expected, result)
+	equals(t, []squashedCommit{
+		{Commits: commits[0:2], Representative: commits[1]},
+		{Commits: commits[2:4], Representative: commits[2]},
+	}, result)
 }
 
-func TestSquashWipCommitGitEditor(t *testing.T) {
-	configuration := getDefaultConfiguration()
-	createTestbed(t, configuration)
-	input := createFile(t, "commits", fmt.Sprintf(
-		`# This is a combination of 2 commits.
-# This is the 1st commit message:
-
-%s
-
-# This is the commit message #2:
-
-new file
-
-# Please enter the commit message for your changes. Lines starting`, configuration.WipCommitMessage))
-	expected := fmt.Sprintf(
-		`# This is a combination of 2 commits.
-# This is the 1st commit message:
-
-# %s
-
-# This is the commit message #2:
-
-new file
-
-# Please enter the commit message for your changes. Lines starting`, configuration.WipCommitMessage)
-
-	squashWipGitEditor(input, getDefaultConfiguration())
-
-	result, _ := ioutil.ReadFile(input)
-	equals(t, expected, string(result))
+func manual(hash string) wipBranchCommit {
+	return wipBranchCommit{Hash: hash, Message: "manual commit " + hash + "\n"}
 }
 
-func TestSquashWipCommitGitSequenceEditor(t *testing.T) {
-	configuration := getDefaultConfiguration()
-	createTestbed(t, configuration)
-	input := createFile(t, "rebase", fmt.Sprintf(
-		`pick 01a9a31 %[1]s
-pick 01a9a32 %[1]s
-pick 01a9a33 %[1]s
-pick c51a56d manual commit
-
-# Rebase ...
-`, configuration.WipCommitMessage))
-	expected := fmt.Sprintf(
-		`pick 01a9a31 %[1]s
-squash 01a9a32 %[1]s
-squash 01a9a33 %[1]s
-squash c51a56d manual commit
-
-# Rebase ...
-`, configuration.WipCommitMessage)
-
-	squashWipGitSequenceEditor(input, getDefaultConfiguration())
-
-	result, _ := ioutil.ReadFile(input)
-	equals(t, expected, string(result))
+func wip(hash string, configuration Configuration) wipBranchCommit {
+	return wipBranchCommit{Hash: hash, Message: configuration.WipCommitMessage + "\n"}
 }
 
 func wipCommit(t *testing.T, configuration Configuration, filename string) {
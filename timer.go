@@ -52,11 +52,8 @@ func getMobTimerRoom(configuration Configuration) string {
 	currentWipBranchQualifier := configuration.WipBranchQualifier
 	if currentWipBranchQualifier == "" {
 		currentBranch := gitCurrentBranch()
-		currentBaseBranch, _ := determineBranches(currentBranch, gitBranches(), configuration)
-
-		if currentBranch.IsWipBranch(configuration) {
-			wipBranchWithoutWipPrefix := currentBranch.removeWipPrefix(configuration).Name
-			currentWipBranchQualifier = removePrefix(removePrefix(wipBranchWithoutWipPrefix, currentBaseBranch.Name), configuration.WipBranchQualifierSeparator)
+		if _, qualifier, ok := parseWipBranch(currentBranch, gitBranches(), configuration); ok {
+			currentWipBranchQualifier = qualifier
 		}
 	}
 
@@ -64,6 +61,11 @@ func getMobTimerRoom(configuration Configuration) string {
 		sayInfo("Using wip branch qualifier for room name")
 		return currentWipBranchQualifier
 	}
+	if configuration.TimerRoom == "" {
+		if meta, recorded := readSessionMeta(gitCurrentBranch()); recorded {
+			return meta.TimerRoom // join the room of the session
+		}
+	}
 	return configuration.TimerRoom
 }
 
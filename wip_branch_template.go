@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A wip branch template, e.g., mob/{base}-{qualifier} or pair/{user}/{qualifier}, defines the name of the wip branch.
+// Without a qualifier, the placeholder and the separator in front of it are left out, e.g., mob/{base}.
+
+type wipBranchTemplate string
+
+const (
+	templateBase      = "{base}"
+	templateQualifier = "{qualifier}"
+	templateUser      = "{user}"
+)
+
+var templatePlaceholder = regexp.MustCompile(`\{(base|qualifier|user)\}`)
+
+// MOB_WIP_BRANCH_TEMPLATE, or the template made of MOB_WIP_BRANCH_PREFIX and MOB_WIP_BRANCH_QUALIFIER_SEPARATOR
+func (c Configuration) wipBranchTemplate() wipBranchTemplate {
+	if c.WipBranchTemplate != "" {
+		return wipBranchTemplate(c.WipBranchTemplate)
+	}
+	if c.customFixedBaseBranchConfigured() && c.customWipBranchQualifierConfigured() {
+		return wipBranchTemplate(c.WipBranchPrefix + templateQualifier)
+	}
+	return wipBranchTemplate(c.WipBranchPrefix + templateBase + c.WipBranchQualifierSeparator + templateQualifier)
+}
+
+// Without {qualifier}, the wip branches of all sessions on a base branch would have the same name, and without any
+// placeholder, a wip branch could never be told apart from any other branch.
+func (template wipBranchTemplate) validate() error {
+	if !templatePlaceholder.MatchString(string(template)) {
+		return errors.New("it has no placeholder")
+	}
+	if !template.has(templateQualifier) {
+		return errors.New("it has no " + templateQualifier + " placeholder")
+	}
+	return nil
+}
+
+func (template wipBranchTemplate) has(placeholder string) bool {
+	return strings.Contains(string(template), placeholder)
+}
+
+func (template wipBranchTemplate) withoutQualifier() wipBranchTemplate {
+	index := strings.Index(string(template), templateQualifier)
+	if index < 0 {
+		return template
+	}
+	start := index
+	for start > 0 && isTemplateSeparator(template[start-1]) {
+		start--
+	}
+	return template[:start] + template[index+len(templateQualifier):]
+}
+
+func isTemplateSeparator(character byte) bool {
+	return !(character >= 'a' && character <= 'z') && !(character >= 'A' && character <= 'Z') && !(character >= '0' && character <= '9') && character != '}'
+}
+
+func (template wipBranchTemplate) render(base string, qualifier string, user string) string {
+	if qualifier == "" {
+		template = template.withoutQualifier()
+	}
+	return strings.NewReplacer(templateBase, base, templateQualifier, qualifier, templateUser, user).Replace(string(template))
+}
+
+// the patterns of all wip branches of the base branch, for git ls-remote
+func (template wipBranchTemplate) globs(base string) []string {
+	globs := []string{template.render(base, "*", "*")}
+	if withoutQualifier := template.render(base, "", "*"); withoutQualifier != globs[0] {
+		globs = append(globs, withoutQualifier)
+	}
+	return globs
+}
+
+// matches the name against the template with and without qualifier; fixed values must match exactly
+func (template wipBranchTemplate) match(name string, fixed map[string]string) (values map[string]string, ok bool) {
+	candidates := []wipBranchTemplate{template.withoutQualifier(), template}
+	if _, qualified := fixed["qualifier"]; qualified {
+		candidates = candidates[1:]
+	}
+	for _, candidate := range candidates {
+		if !templatePlaceholder.MatchString(string(candidate)) {
+			continue
+		}
+		if values, ok = candidate.pattern(fixed).matchValues(name); ok {
+			return values, ok
+		}
+	}
+	return nil, false
+}
+
+type templatePattern struct {
+	*regexp.Regexp
+}
+
+// the compiled patterns by template and fixed values, as every check whether a branch is a wip branch needs one
+var templatePatterns = map[string]templatePattern{}
+
+// placeholders which are not fixed match as few characters as possible, so the base branch is the shortest possible
+func (template wipBranchTemplate) pattern(fixed map[string]string) templatePattern {
+	key := template.patternKey(fixed)
+	if cached, ok := templatePatterns[key]; ok {
+		return cached
+	}
+	compiled := template.compilePattern(fixed)
+	templatePatterns[key] = compiled
+	return compiled
+}
+
+func (template wipBranchTemplate) patternKey(fixed map[string]string) string {
+	names := make([]string, 0, len(fixed))
+	for name := range fixed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	key := string(template)
+	for _, name := range names {
+		key += "\x00" + name + "=" + fixed[name]
+	}
+	return key
+}
+
+func (template wipBranchTemplate) compilePattern(fixed map[string]string) templatePattern {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, match := range templatePlaceholder.FindAllStringSubmatchIndex(string(template), -1) {
+		pattern.WriteString(regexp.QuoteMeta(string(template[last:match[0]])))
+		name := string(template[match[2]:match[3]])
+		if value, isFixed := fixed[name]; isFixed {
+			pattern.WriteString("(?P<" + name + ">" + regexp.QuoteMeta(value) + ")")
+		} else if name == "user" {
+			pattern.WriteString("(?P<user>[^/]+?)")
+		} else {
+			pattern.WriteString("(?P<" + name + ">.+?)")
+		}
+		last = match[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(string(template[last:])) + "$")
+	return templatePattern{regexp.MustCompile(pattern.String())}
+}
+
+func (pattern templatePattern) matchValues(name string) (map[string]string, bool) {
+	match := pattern.FindStringSubmatch(name)
+	if match == nil {
+		return nil, false
+	}
+	values := map[string]string{}
+	for i, group := range pattern.SubexpNames() {
+		if group != "" {
+			values[group] = match[i]
+		}
+	}
+	return values, true
+}
+
+// determines base branch and qualifier of a wip branch. As the separator may be part of the base branch, too,
+// the base branch recorded in the session metadata, the fixed base branch, the configured qualifier and the local
+// branches are tried first.
+func parseWipBranch(wipBranch Branch, localBranches []string, configuration Configuration) (baseBranch Branch, qualifier string, ok bool) {
+	template := configuration.wipBranchTemplate()
+	values, ok := template.match(wipBranch.Name, nil)
+	if !ok {
+		return Branch{}, "", false
+	}
+
+	meta, _ := readSessionMeta(wipBranch)
+	recordedBase := meta.BaseBranch
+	if !template.has(templateBase) {
+		switch {
+		case recordedBase != "":
+			return newBranch(recordedBase), values["qualifier"], true
+		case configuration.customFixedBaseBranchConfigured():
+			return newBranch(configuration.FixedBaseBranch), values["qualifier"], true
+		default:
+			return defaultBranch(localBranches, configuration), values["qualifier"], true
+		}
+	}
+
+	var candidates []map[string]string
+	for _, base := range []string{recordedBase, configuration.FixedBaseBranch} {
+		if base != "" {
+			candidates = append(candidates, map[string]string{"base": base})
+		}
+	}
+	if configuration.customWipBranchQualifierConfigured() {
+		candidates = append(candidates, map[string]string{"qualifier": configuration.WipBranchQualifier})
+	}
+	longestFirst := append([]string{}, localBranches...)
+	sort.SliceStable(longestFirst, func(i, j int) bool { return len(longestFirst[i]) > len(longestFirst[j]) })
+	for _, base := range longestFirst {
+		if base != "" && base != wipBranch.Name {
+			candidates = append(candidates, map[string]string{"base": base})
+		}
+	}
+
+	for _, fixed := range candidates {
+		if candidate, matches := template.match(wipBranch.Name, fixed); matches && candidate["base"] != "" {
+			return newBranch(candidate["base"]), candidate["qualifier"], true
+		}
+	}
+	if candidate, matches := template.pattern(nil).matchValues(wipBranch.Name); matches {
+		return newBranch(candidate["base"]), candidate["qualifier"], true
+	}
+	return newBranch(values["base"]), values["qualifier"], true
+}
+
+// the qualifier of a wip branch of the given base branch
+func sessionQualifier(wipBranch Branch, baseBranch Branch, configuration Configuration) (qualifier string, ok bool) {
+	if configuration.LegacySessionBranch && wipBranch.Is("mob-session") {
+		// DEPRECATED
+		return "", baseBranch.Is("master")
+	}
+	values, ok := configuration.wipBranchTemplate().match(wipBranch.Name, map[string]string{"base": baseBranch.Name})
+	return values["qualifier"], ok
+}
+
+func wipBranchUser(template wipBranchTemplate) string {
+	if !template.has(templateUser) {
+		return ""
+	}
+	return refNameComponent(gitUserName())
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBranchRemoteWithWipRemote(t *testing.T) {
+	configuration := getDefaultConfiguration()
+	configuration.WipRemoteName = "team"
+
+	equals(t, "origin/master", newBranch("master").remote(configuration).Name)
+	equals(t, "team/mob/master-green", newBranch("mob/master-green").remote(configuration).Name)
+	equals(t, "team/mob-session", newBranch("mob-session").remote(configuration).Name)
+}
+
+func TestWipRemoteNameDefaultsToRemoteName(t *testing.T) {
+	configuration := getDefaultConfiguration()
+	configuration.RemoteName = "upstream"
+
+	equals(t, "upstream", configuration.wipRemoteName())
+	equals(t, "upstream/mob/master", newBranch("mob/master").remote(configuration).Name)
+}
+
+func TestStartWithWipRemote(t *testing.T) {
+	_, configuration := setup(t)
+	configuration = setupWipRemote(t, configuration)
+
+	start(configuration)
+
+	assertOnBranch(t, "mob-session")
+	equals(t, "", silentgit("ls-remote", "--heads", "origin", "mob-session"))
+	equals(t, true, strings.HasSuffix(silentgit("ls-remote", "--heads", "team", "mob-session"), "refs/heads/mob-session"))
+	equals(t, "team/mob-session", silentgit("rev-parse", "--abbrev-ref", "mob-session@{upstream}"))
+	equals(t, true, strings.HasSuffix(silentgit("ls-remote", "team", metaRef(newBranch("mob-session"))), metaRef(newBranch("mob-session"))))
+	equals(t, "", silentgit("ls-remote", "origin", metaRef(newBranch("mob-session"))))
+}
+
+func TestNextAndDoneWithWipRemote(t *testing.T) {
+	_, configuration := setup(t)
+	configuration = setupWipRemote(t, configuration)
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	start(configuration)
+	assertOnBranch(t, "mob-session")
+	assertFileExist(t, "file1.txt")
+	done(configuration)
+
+	assertOnBranch(t, "master")
+	assertGitStatus(t, map[string]string{"file1.txt": "A"})
+	equals(t, "", silentgit("ls-remote", "--heads", "team", "mob-session"))
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+}
+
+func TestFetchBranchesCommandsWithWipRemote(t *testing.T) {
+	_, configuration := setup(t)
+	configuration = setupWipRemote(t, configuration)
+	start(configuration)
+
+	commands, missingRemoteBranches := fetchBranchesCommands(configuration, newBranch("master"), newBranch("mob-session"), newBranch("mob/gone"))
+
+	equals(t, [][]string{
+		{"fetch", "--prune", "origin", "+refs/heads/master:refs/remotes/origin/master"},
+		{"fetch", "--prune", "team", "+refs/mob/*:refs/mob/*", "+refs/heads/mob-session:refs/remotes/team/mob-session"},
+	}, commands)
+	equals(t, []string{"team/mob/gone"}, missingRemoteBranches)
+}
+
+// adds the remote 'team' for the wip branches to the clones local and localother
+func setupWipRemote(t *testing.T, configuration Configuration) Configuration {
+	run(t, "git", "init", "--bare", "--quiet", tempDir+"/team")
+	for _, clone := range []string{"local", "localother"} {
+		setWorkingDir(tempDir + "/" + clone)
+		git("remote", "add", "team", tempDir+"/team")
+	}
+	setWorkingDir(tempDir + "/local")
+	configuration.WipRemoteName = "team"
+	return configuration
+}
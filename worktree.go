@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 'mob start --worktree' runs the session in a linked git worktree, so your own checkout stays untouched.
+// The path of the worktree is recorded in the git config of the wip branch.
+
+func startInWorktree(configuration Configuration, currentBaseBranch Branch, currentWipBranch Branch) error {
+	path := configuration.StartWorktreePath
+	if path == "" {
+		path = defaultWorktreePath(currentWipBranch)
+	}
+	path, _ = filepath.Abs(path)
+	if _, err := os.Stat(path); err == nil {
+		sayError("cannot start; '" + path + "' already exists")
+		sayFix("To start in another directory, use", configuration.mob("start --worktree <path>"))
+		return errors.New("cannot start; worktree path already exists")
+	}
+
+	joining := currentWipBranch.hasRemoteBranch(configuration)
+	if joining {
+		sayInfo("joining existing session from " + currentWipBranch.remote(configuration).String() + " in worktree " + path)
+		if err := gitignorefailure("worktree", "add", "-B", currentWipBranch.Name, path, currentWipBranch.remote(configuration).Name); err != nil {
+			return err
+		}
+	} else {
+		warnForActiveWipBranches(configuration, currentBaseBranch)
+		from := currentBaseBranch.remote(configuration).Name
+		if configuration.StartFrom != "" {
+			from = configuration.StartFrom
+		}
+		sayInfo("starting new session from " + from + " in worktree " + path)
+		if err := gitignorefailure("worktree", "add", "-B", currentWipBranch.Name, path, from); err != nil {
+			return err
+		}
+		if err := recordSessionStart(configuration, currentWipBranch, currentBaseBranch, from); err != nil {
+			return err
+		}
+	}
+
+	workingDir = path
+	var err error
+	if joining {
+		err = gitignorefailure("branch", "--set-upstream-to="+currentWipBranch.remote(configuration).Name, currentWipBranch.Name)
+	} else {
+		err = gitignorefailure(deleteEmptyStrings([]string{"push", configuration.gitHooksOption(), "--set-upstream", configuration.wipRemoteName(), currentWipBranch.Name})...)
+	}
+	if err != nil {
+		return err
+	}
+	silentgit("config", "branch."+currentWipBranch.Name+".mobWorktree", path)
+	if err := registerParticipant(configuration, currentWipBranch); err != nil {
+		return err
+	}
+
+	sayInfo("you are on wip branch '" + currentWipBranch.String() + "' (base branch '" + currentBaseBranch.String() + "') in worktree " + path)
+	sayLastCommitsList(currentBaseBranch.String(), currentWipBranch.String())
+	sayNext("To work in the session, use", "cd "+path)
+	return nil
+}
+
+// a sibling of the main worktree, e.g., ../project-mob-main for the wip branch mob/main of ../project
+func defaultWorktreePath(wipBranch Branch) string {
+	main := mainWorktreeDir()
+	return filepath.Join(filepath.Dir(main), filepath.Base(main)+"-"+refNameComponent(wipBranch.Name))
+}
+
+func mainWorktreeDir() string {
+	for _, line := range strings.Split(silentgit("worktree", "list", "--porcelain"), "\n") {
+		if strings.HasPrefix(line, "worktree ") {
+			return strings.TrimPrefix(line, "worktree ")
+		}
+	}
+	return ""
+}
+
+func sessionWorktree(wipBranch Branch) string {
+	return silentgitignorefailure("config", "--get", "branch."+wipBranch.Name+".mobWorktree")
+}
+
+// returns the path of the session worktree if the current working directory is inside of it
+func currentSessionWorktree(wipBranch Branch) string {
+	if !isLinkedWorktree() {
+		return ""
+	}
+	worktree := sessionWorktree(wipBranch)
+	if worktree == "" || !isSameDir(worktree, gitRootDir()) {
+		return ""
+	}
+	return worktree
+}
+
+func isSameDir(a string, b string) bool {
+	a, errA := filepath.EvalSymlinks(a)
+	b, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && a == b
+}
+
+// 'mob done' merges into the base branch in the main worktree, so it must be on the base branch without changes
+// to tracked files. Untracked files don't get in the way of the merge, so they stay where they are.
+func ensureMainWorktreeCanMerge(configuration Configuration, baseBranch Branch) error {
+	main := mainWorktreeDir()
+	if mainBranch := silentgit("-C", main, "rev-parse", "--abbrev-ref", "HEAD"); mainBranch != baseBranch.Name {
+		sayError("cannot finish the session; the main worktree " + main + " is on '" + mainBranch + "', but 'mob done' merges into '" + baseBranch.Name + "' there")
+		sayFix("To finish the session, switch the main worktree to the base branch and try again with", "git -C "+main+" checkout "+baseBranch.Name)
+		return errors.New("cannot finish the session; the main worktree is not on the base branch")
+	}
+	if silentgit("-C", main, "status", "--short", "--untracked-files=no") != "" {
+		sayError("cannot finish the session; the main worktree " + main + " has uncommitted changes")
+		sayFix("To finish the session, commit or stash them and try again with", configuration.mob("done"))
+		return errors.New("cannot finish the session; uncommitted changes in the main worktree")
+	}
+	return nil
+}
+
+// continues in the main worktree and removes the session worktree
+func leaveSessionWorktree(worktree string, force bool) {
+	main := mainWorktreeDir()
+	sayInfo("leaving the session worktree " + worktree + " for the main worktree " + main)
+	workingDir = main
+	if _, err := os.Stat(worktree); err != nil {
+		silentgit("worktree", "prune")
+	} else if force {
+		git("worktree", "remove", "--force", worktree)
+	} else {
+		git("worktree", "remove", worktree)
+	}
+	sayNext("The session worktree is gone, so continue in the main worktree with", "cd "+main)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStartWorktree(t *testing.T) {
+	output, configuration := setup(t)
+	createFile(t, "personal.txt", "contentIrrelevant")
+	configuration.StartWorktree = true
+	configuration.StartWorktreePath = tempDir + "/local-session"
+
+	err := start(configuration)
+
+	equals(t, nil, err)
+	assertOnBranch(t, "mob-session")
+	equals(t, tempDir+"/local-session", workingDir)
+	assertOutputContains(t, output, "cd "+tempDir+"/local-session")
+	setWorkingDir(tempDir + "/local")
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{"personal.txt": "??"})
+}
+
+func TestStartWorktreeNextDone(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.StartWorktree = true
+	configuration.StartWorktreePath = tempDir + "/local-session"
+	configuration.NextStay = false
+	start(configuration)
+
+	createFile(t, "file1.txt", "contentIrrelevant")
+	next(configuration)
+	assertOnBranch(t, "mob-session")
+
+	createFile(t, "file2.txt", "contentIrrelevant")
+	done(configuration)
+
+	equals(t, tempDir+"/local", workingDir)
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	assertGitStatus(t, GitStatus{
+		"file1.txt": "A",
+		"file2.txt": "A",
+	})
+	_, err := os.Stat(tempDir + "/local-session")
+	equals(t, true, os.IsNotExist(err))
+}
+
+func TestStartWorktreeDoneRefusesWithDirtyMainWorktree(t *testing.T) {
+	output, configuration := setup(t)
+	createFile(t, "test.txt", "personal change")
+	configuration.StartWorktree = true
+	configuration.StartWorktreePath = tempDir + "/local-session"
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+
+	done(configuration)
+
+	assertOnBranch(t, "mob-session")
+	assertOutputContains(t, output, "cannot finish the session; the main worktree "+tempDir+"/local has uncommitted changes")
+}
+
+func TestStartWorktreeDoneKeepsUntrackedFilesOfMainWorktree(t *testing.T) {
+	_, configuration := setup(t)
+	createFile(t, "personal.txt", "contentIrrelevant")
+	configuration.StartWorktree = true
+	configuration.StartWorktreePath = tempDir + "/local-session"
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+
+	done(configuration)
+
+	equals(t, tempDir+"/local", workingDir)
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{
+		"file1.txt":    "A",
+		"personal.txt": "??",
+	})
+}
+
+func TestStartWorktreeDoneRefusesWhenMainWorktreeIsOnAnotherBranch(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.StartWorktree = true
+	configuration.StartWorktreePath = tempDir + "/local-session"
+	start(configuration)
+	setWorkingDir(tempDir + "/local")
+	git("checkout", "-b", "personal")
+	setWorkingDir(tempDir + "/local-session")
+	createFile(t, "file1.txt", "contentIrrelevant")
+
+	done(configuration)
+
+	assertOnBranch(t, "mob-session")
+	assertOutputContains(t, output, "cannot finish the session; the main worktree "+tempDir+"/local is on 'personal', but 'mob done' merges into 'master' there")
+	setWorkingDir(tempDir + "/local")
+	assertOnBranch(t, "personal")
+}
+
+func TestStartWorktreeReset(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.StartWorktree = true
+	configuration.StartWorktreePath = tempDir + "/local-session"
+	start(configuration)
+	createFile(t, "file1.txt", "contentIrrelevant")
+
+	reset(configuration)
+
+	equals(t, tempDir+"/local", workingDir)
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	_, err := os.Stat(tempDir + "/local-session")
+	equals(t, true, os.IsNotExist(err))
+}
+
+func TestParseArgsWorktree(t *testing.T) {
+	_, _, configuration := parseArgs([]string{"mob", "start", "--worktree", "../session"}, getDefaultConfiguration())
+	equals(t, true, configuration.StartWorktree)
+	equals(t, "../session", configuration.StartWorktreePath)
+
+	_, parameters, configuration := parseArgs([]string{"mob", "start", "--worktree", "10"}, getDefaultConfiguration())
+	equals(t, true, configuration.StartWorktree)
+	equals(t, "", configuration.StartWorktreePath)
+	equals(t, []string{"10"}, parameters)
+}